@@ -0,0 +1,61 @@
+package config
+
+import "fmt"
+
+// CollisionMode decides how an entity is tested for contact with other entities
+type CollisionMode int
+
+const (
+	CollideTile   CollisionMode = iota // entities touch when they share a tile, as in the arcade
+	CollideCircle                      // circles of the collider size overlap
+	CollideBox                         // axis-aligned squares of the collider half-width overlap
+)
+
+func (m CollisionMode) String() string {
+	switch m {
+	case CollideTile:
+		return "tile"
+	case CollideCircle:
+		return "circle"
+	case CollideBox:
+		return "aabb"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCollisionMode returns the collision mode with the given name
+func ParseCollisionMode(name string) (CollisionMode, error) {
+	for mode := CollideTile; mode <= CollideBox; mode++ {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown collision mode %q", name)
+}
+
+func (m CollisionMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *CollisionMode) UnmarshalText(text []byte) error {
+	mode, err := ParseCollisionMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// ColliderConfig describes an entity's collider in pixels
+type ColliderConfig struct {
+	Mode CollisionMode `json:"mode"`
+	Size float64       `json:"size"` // radius for circle, half-width for aabb; unused by tile
+	Body float64       `json:"body"` // half-width of the square kept clear of walls
+}
+
+// Colliders holds the collider of each kind of entity
+type Colliders struct {
+	Player ColliderConfig `json:"player"`
+	Ghost  ColliderConfig `json:"ghost"`
+}
@@ -1,21 +1,41 @@
 package config
 
+import "fmt"
+
+// Difficulty is an index into the difficulty presets of the active rules
 type Difficulty int
 
+// Indices of the built-in presets
 const (
 	DifficultyEasy Difficulty = iota
 	DifficultyMedium
 	DifficultyHard
+	DifficultyGenius
+)
+
+// GameMode selects the rules a run is played under
+type GameMode int
+
+const (
+	ModeClassic    GameMode = iota // real-time arcade play
+	ModeTurnBased                  // everyone moves one tile per turn after the player
+	ModeTimeAttack                 // real-time play against the clock and the personal best
+	ModeSurvival                   // endless play against waves of ever more dangerous ghosts
 )
 
-func (d Difficulty) String() string {
-	switch d {
-	case DifficultyEasy:
-		return "Easy"
-	case DifficultyMedium:
-		return "Medium"
-	case DifficultyHard:
-		return "Hard"
+// GameModes lists the modes offered in the menu, in order
+var GameModes = []GameMode{ModeClassic, ModeTurnBased, ModeTimeAttack, ModeSurvival}
+
+func (m GameMode) String() string {
+	switch m {
+	case ModeClassic:
+		return "Classic"
+	case ModeTurnBased:
+		return "Turn-based"
+	case ModeTimeAttack:
+		return "Time attack"
+	case ModeSurvival:
+		return "Survival"
 	default:
 		return "Unknown"
 	}
@@ -27,7 +47,8 @@ const (
 	GhostSkillLevelDumb   GhostLevel = iota // Random movement, ignores player
 	GhostSkillLevelSlow                     // Follows player but makes mistakes
 	GhostSkillLevelNormal                   // Standard BFS pathfinding
-	GhostSkillLevelSmart                    // Optimized pathfinding with prediction
+	GhostSkillLevelSmart                    // Optimized pathfinding, avoids dead ends
+	GhostSkillLevelGenius                   // Anticipates the player and favors junctions
 )
 
 func (s GhostLevel) String() string {
@@ -40,61 +61,57 @@ func (s GhostLevel) String() string {
 		return "Normal"
 	case GhostSkillLevelSmart:
 		return "Smart"
+	case GhostSkillLevelGenius:
+		return "Genius"
 	default:
 		return "Unknown"
 	}
 }
 
-type DifficultyConfig struct {
-	Name        string
-	Description string
-	GhostSpeeds []float64
-	SkillLevels []GhostLevel
-	RecalcEvery int // Frames between BFS recalculations
+// ParseGhostLevel returns the skill level with the given name
+func ParseGhostLevel(name string) (GhostLevel, error) {
+	for level := GhostSkillLevelDumb; level <= GhostSkillLevelGenius; level++ {
+		if level.String() == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown ghost skill level %q", name)
 }
 
-func GetDifficultyConfig(difficulty Difficulty) DifficultyConfig {
-	switch difficulty {
-	case DifficultyEasy:
-		return DifficultyConfig{
-			Name:        "Easy",
-			Description: "Ghosts are slow and not very smart",
-			GhostSpeeds: []float64{1.0, 1.1, 1.0, 0.9},
-			SkillLevels: []GhostLevel{
-				GhostSkillLevelDumb, // Blinky: Random movement
-				GhostSkillLevelSlow, // Pinky: Makes mistakes
-				GhostSkillLevelDumb, // Inky: Random movement
-				GhostSkillLevelSlow, // Clyde: Makes mistakes
-			},
-			RecalcEvery: 12, // Slower rate
-		}
-	case DifficultyMedium:
-		return DifficultyConfig{
-			Name:        "Medium",
-			Description: "Balanced gameplay with mixed ghost abilities",
-			GhostSpeeds: []float64{1.3, 1.4, 1.2, 1.3}, // Medium speeds
-			SkillLevels: []GhostLevel{
-				GhostSkillLevelNormal, // Blinky: Standard intelligence
-				GhostSkillLevelSlow,   // Pinky: Makes some mistakes
-				GhostSkillLevelNormal, // Inky: Standard intelligence
-				GhostSkillLevelSlow,   // Clyde: Makes some mistakes
-			},
-			RecalcEvery: 8, // Medium update rate
-		}
-	case DifficultyHard:
-		return DifficultyConfig{
-			Name:        "Hard",
-			Description: "Fast and intelligent ghosts",
-			GhostSpeeds: []float64{1.5, 1.6, 1.4, 1.5}, // Faster ghosts
-			SkillLevels: []GhostLevel{
-				GhostSkillLevelSmart,  // Blinky: Smart intelligence
-				GhostSkillLevelNormal, // Pinky: Standard intelligence
-				GhostSkillLevelSmart,  // Inky: Smart intelligence
-				GhostSkillLevelNormal, // Clyde: Standard intelligence
-			},
-			RecalcEvery: 6, // Standard update rate
-		}
-	default:
-		return GetDifficultyConfig(DifficultyMedium)
+func (s GhostLevel) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *GhostLevel) UnmarshalText(text []byte) error {
+	level, err := ParseGhostLevel(string(text))
+	if err != nil {
+		return err
 	}
+	*s = level
+	return nil
+}
+
+// GhostAlgorithms lists the targeting algorithm names presets may assign
+var GhostAlgorithms = []string{"Chase", "Scatter", "Frightened", "Patrol", "Ambush", "Random"}
+
+type DifficultyConfig struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	GhostSpeeds []float64    `json:"ghost_speeds"` // Tiles per second per ghost
+	SkillLevels []GhostLevel `json:"skill_levels"` // Skill modifier per ghost
+	Algorithms  []string     `json:"algorithms"`   // Targeting algorithm per ghost
+	RecalcEvery int          `json:"recalc_every"` // Ticks between BFS recalculations
+}
+
+// AdaptiveBounds limits how far adaptive mode may move away from the chosen
+// preset and tunes how it judges the player
+type AdaptiveBounds struct {
+	MinSpeedScale     float64 `json:"min_speed_scale"`
+	MaxSpeedScale     float64 `json:"max_speed_scale"`
+	MaxTierShift      int     `json:"max_tier_shift"`
+	WindowTime        float64 `json:"window_time"`         // seconds between evaluations
+	TargetPelletRate  float64 `json:"target_pellet_rate"`  // pellets per second a comfortable player collects
+	LongSurvivalTime  float64 `json:"long_survival_time"`  // seconds without a catch that count as doing well
+	IntensityStep     float64 `json:"intensity_step"`      // largest change of intensity per evaluation
+	NearMissTolerance int     `json:"near_miss_tolerance"` // near-misses per window before easing off
 }
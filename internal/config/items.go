@@ -0,0 +1,165 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// ItemDef defines a kind of pickup. Items are placed in the maze by their
+// spawn rules and looked up by ID when collected or drawn.
+type ItemDef struct {
+	Sprite   string         `json:"sprite"`   // sprite name; items without a known sprite are drawn as a dot
+	Collider ColliderConfig `json:"collider"` // shape the player has to touch to collect the item
+	Score    int            `json:"score"`    // points for collecting the item
+	Effects  []string       `json:"effects"`  // power-ups granted on collection, from PowerUps
+	History  bool           `json:"history"`  // collected items are listed in the HUD, like the arcade's fruit row
+	Spawn    ItemSpawn      `json:"spawn"`
+}
+
+// ItemSpawn decides when and for how long an item appears
+type ItemSpawn struct {
+	AtPellets []int   `json:"at_pellets"` // pellets eaten when a copy appears, ascending
+	Time      float64 `json:"time"`       // seconds a copy stays before it vanishes; 0 keeps it until collected
+	FromLevel int     `json:"from_level"` // first level the item appears on
+	ToLevel   int     `json:"to_level"`   // last level the item appears on; 0 for every later level
+	Respawn   float64 `json:"respawn"`    // seconds after a copy is collected or vanishes until another appears; 0 for never
+	Strategy  string  `json:"strategy"`   // placement strategy for this item, overriding the spawn rules; empty to follow them
+}
+
+// SpawnStrategies lists the ways the tile for a new item may be picked,
+// sorted. The built-in strategies are always known, so rules validate the
+// same without the spawn package; strategies it registers later are added.
+var SpawnStrategies = []string{"center", "distant", "fair", "random", "unvisited"}
+
+// RegisterSpawnStrategy makes a placement strategy name valid in the rules
+func RegisterSpawnStrategy(name string) {
+	if !slices.Contains(SpawnStrategies, name) {
+		SpawnStrategies = append(SpawnStrategies, name)
+		slices.Sort(SpawnStrategies)
+	}
+}
+
+// SpawnRules picks the placement strategy for items
+type SpawnRules struct {
+	Levels      []string          `json:"levels"`       // strategy per level; the last entry applies to all later levels
+	Modes       map[string]string `json:"modes"`        // strategy per game mode name, overriding the level's
+	MinDistance int               `json:"min_distance"` // tiles of path the distant strategy keeps from the player and ghosts
+}
+
+// OnLevel reports whether the item appears on the given level
+func (d ItemDef) OnLevel(levelNumber int) bool {
+	return levelNumber >= d.Spawn.FromLevel && (d.Spawn.ToLevel == 0 || levelNumber <= d.Spawn.ToLevel)
+}
+
+// SpawnStrategyFor returns the placement strategy for an item on a 1-based
+// level number in the given game mode
+func (r *Rules) SpawnStrategyFor(item ItemDef, levelNumber int, mode GameMode) string {
+	if item.Spawn.Strategy != "" {
+		return item.Spawn.Strategy
+	}
+	if strategy, ok := r.Spawn.Modes[mode.String()]; ok {
+		return strategy
+	}
+	i := min(max(levelNumber-1, 0), len(r.Spawn.Levels)-1)
+	return r.Spawn.Levels[i]
+}
+
+// ItemIDs returns the IDs of every defined item in a stable order
+func (r *Rules) ItemIDs() []string {
+	return slices.Sorted(maps.Keys(r.Items))
+}
+
+// defaultItems returns the arcade's bonus fruit, one kind per level range
+func defaultItems() map[string]ItemDef {
+	fruit := func(score int, effects []string, from, to int) ItemDef {
+		return ItemDef{
+			Collider: ColliderConfig{Mode: CollideCircle, Size: 2},
+			Score:    score,
+			Effects:  effects,
+			History:  true,
+			Spawn:    ItemSpawn{AtPellets: []int{30, 75}, Time: 9.5, FromLevel: from, ToLevel: to},
+		}
+	}
+
+	items := map[string]ItemDef{
+		"cherry":     fruit(100, []string{"speed"}, 1, 1),
+		"strawberry": fruit(300, []string{"magnet"}, 2, 2),
+		"orange":     fruit(500, []string{"freeze"}, 3, 4),
+		"apple":      fruit(700, []string{"speed"}, 5, 6),
+		"melon":      fruit(1000, []string{"shield"}, 7, 8),
+		"galaxian":   fruit(2000, []string{"phasing"}, 9, 10),
+		"bell":       fruit(3000, []string{"invisibility"}, 11, 12),
+		"key":        fruit(5000, []string{"shield", "phasing"}, 13, 0),
+	}
+	for id, def := range items {
+		def.Sprite = id
+		items[id] = def
+	}
+	return items
+}
+
+// validateItems checks every item definition
+func validateItems(items map[string]ItemDef, powerUps map[string]PowerUpConfig, fail func(string, ...any)) {
+	for id, def := range items {
+		validateCollider("items."+id+".collider", def.Collider, false, fail)
+		if def.Score < 0 {
+			fail("items.%s.score must not be negative, got %d", id, def.Score)
+		}
+		for _, effect := range def.Effects {
+			if _, ok := powerUps[effect]; !ok {
+				fail("items.%s.effects: no power_ups entry for %q", id, effect)
+			}
+		}
+
+		spawn := def.Spawn
+		for i, pellets := range spawn.AtPellets {
+			if pellets <= 0 || (i > 0 && pellets <= spawn.AtPellets[i-1]) {
+				fail("items.%s.spawn.at_pellets must be positive and ascending, got %v", id, spawn.AtPellets)
+				break
+			}
+		}
+		if spawn.Time < 0 {
+			fail("items.%s.spawn.time must not be negative, got %v", id, spawn.Time)
+		}
+		if spawn.FromLevel < 1 {
+			fail("items.%s.spawn.from_level must be at least 1, got %d", id, spawn.FromLevel)
+		}
+		if spawn.ToLevel != 0 && spawn.ToLevel < spawn.FromLevel {
+			fail("items.%s.spawn.to_level must be 0 or at least from_level, got %d", id, spawn.ToLevel)
+		}
+		if spawn.Respawn < 0 {
+			fail("items.%s.spawn.respawn must not be negative, got %v", id, spawn.Respawn)
+		}
+		if spawn.Strategy != "" && !slices.Contains(SpawnStrategies, spawn.Strategy) {
+			fail("items.%s.spawn.strategy: unknown strategy %q, want one of %v", id, spawn.Strategy, SpawnStrategies)
+		}
+	}
+}
+
+// validateSpawn checks the placement strategies chosen per level and mode
+func validateSpawn(s SpawnRules, fail func(string, ...any)) {
+	if len(s.Levels) == 0 {
+		fail("spawn.levels must contain at least one strategy")
+	}
+	for i, strategy := range s.Levels {
+		if !slices.Contains(SpawnStrategies, strategy) {
+			fail("spawn.levels[%d]: unknown strategy %q, want one of %v", i, strategy, SpawnStrategies)
+		}
+	}
+
+	var modes []string
+	for _, mode := range GameModes {
+		modes = append(modes, mode.String())
+	}
+	for mode, strategy := range s.Modes {
+		if !slices.Contains(modes, mode) {
+			fail("spawn.modes: unknown game mode %q, want one of %v", mode, modes)
+		}
+		if !slices.Contains(SpawnStrategies, strategy) {
+			fail("spawn.modes.%s: unknown strategy %q, want one of %v", mode, strategy, SpawnStrategies)
+		}
+	}
+	if s.MinDistance < 0 {
+		fail("spawn.min_distance must not be negative, got %d", s.MinDistance)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// ObjectiveKinds lists the goals a level may set:
+//   - "pellets" eat Count pellets, or all of them when Count is 0
+//   - "survive" stay in the level for Time seconds
+//   - "fruit" collect every item shown in the HUD history scheduled for the level
+//   - "exit" reach an exit tile, marked 'E' in the maze
+//   - "ghosts" eat Count ghosts; the only way to eat one is to be caught while
+//     shielded, so the level needs an item granting "shield"
+//   - "time_limit" finish within Time seconds
+var ObjectiveKinds = []string{"pellets", "survive", "fruit", "exit", "ghosts", "time_limit"}
+
+// Objective is one goal of a level
+type Objective struct {
+	Kind     string  `json:"kind"`     // one of ObjectiveKinds
+	Count    int     `json:"count"`    // pellets or ghosts to eat
+	Time     float64 `json:"time"`     // seconds to survive, or the time limit
+	Optional bool    `json:"optional"` // earns a star instead of being needed to win
+}
+
+// LevelRules declares a level: its maze and what it takes to win it
+type LevelRules struct {
+	Maze       []string    `json:"maze"`       // rows of '#' wall, '.' pellet, ' ' floor and 'E' exit; empty for the built-in maze
+	Objectives []Objective `json:"objectives"` // the level is won once every required objective is met
+	Require    string      `json:"require"`    // "all" required objectives must be met to win (the default), or "any" one of them
+}
+
+// DefaultMaze is the built-in maze, used by levels that do not set their own
+var DefaultMaze = []string{
+	"#####################",
+	"#...................#",
+	"#.###.#.###.#.###.###",
+	"#.#...#...#...#...#.#",
+	"#.#.#####.#.#####.#.#",
+	"#...................#",
+	"#.###.#.###.#.###.###",
+	"#.#...#...#...#...#.#",
+	"#.#.#####.#.#####.#.#",
+	"#...................#",
+	"#####################",
+}
+
+// LevelFor returns the rules for a 1-based level number
+func (r *Rules) LevelFor(levelNumber int) LevelRules {
+	i := min(max(levelNumber-1, 0), len(r.Levels)-1)
+	return r.Levels[i]
+}
+
+// defaultLevels returns the built-in level: clear the maze, with a star each
+// for doing it quickly and for collecting every fruit
+func defaultLevels() []LevelRules {
+	return []LevelRules{
+		{
+			Objectives: []Objective{
+				{Kind: "pellets"},
+				{Kind: "time_limit", Time: 90, Optional: true},
+				{Kind: "fruit", Optional: true},
+			},
+		},
+	}
+}
+
+// validateLevels checks every level's maze and objectives
+func validateLevels(levels []LevelRules, items map[string]ItemDef, fail func(string, ...any)) {
+	if len(levels) == 0 {
+		fail("levels must contain at least one level")
+	}
+
+	for i, level := range levels {
+		for y, row := range level.Maze {
+			if len(row) != len(level.Maze[0]) {
+				fail("levels[%d].maze row %d is %d tiles wide, want %d", i, y, len(row), len(level.Maze[0]))
+				break
+			}
+		}
+		if len(level.Maze) > 0 && (len(level.Maze) < 3 || len(level.Maze[0]) < 3) {
+			fail("levels[%d].maze must be at least 3 by 3 tiles", i)
+		}
+		if level.Require != "" && level.Require != "all" && level.Require != "any" {
+			fail("levels[%d].require must be \"all\" or \"any\", got %q", i, level.Require)
+		}
+
+		required := 0
+		for j, obj := range level.Objectives {
+			if !slices.Contains(ObjectiveKinds, obj.Kind) {
+				fail("levels[%d].objectives[%d]: unknown kind %q, want one of %v", i, j, obj.Kind, ObjectiveKinds)
+				continue
+			}
+			if obj.Count < 0 || (obj.Kind == "ghosts" && obj.Count == 0) {
+				fail("levels[%d].objectives[%d]: count must be positive for %s, got %d", i, j, obj.Kind, obj.Count)
+			}
+			if (obj.Kind == "survive" || obj.Kind == "time_limit") && obj.Time <= 0 {
+				fail("levels[%d].objectives[%d]: time must be positive for %s, got %v", i, j, obj.Kind, obj.Time)
+			}
+			if pellets := pelletCount(level.Maze); obj.Kind == "pellets" && obj.Count > pellets {
+				fail("levels[%d].objectives[%d]: count %d is more than the %d pellets in the maze", i, j, obj.Count, pellets)
+			}
+			if obj.Kind == "ghosts" && !shieldOnLevel(items, i+1) {
+				fail("levels[%d].objectives[%d]: ghosts needs an item granting \"shield\" on the level", i, j)
+			}
+			if obj.Kind == "exit" && !slices.ContainsFunc(level.Maze, func(row string) bool { return strings.Contains(row, "E") }) {
+				fail("levels[%d].objectives[%d]: exit needs a maze with an 'E' tile", i, j)
+			}
+			// A time limit alone is met from the start, so it cannot win a level by itself
+			if !obj.Optional && obj.Kind != "time_limit" {
+				required++
+			}
+		}
+		if required == 0 {
+			fail("levels[%d] needs at least one required objective besides a time limit", i)
+		}
+	}
+}
+
+// shieldOnLevel reports whether some item on a 1-based level grants a shield
+func shieldOnLevel(items map[string]ItemDef, levelNumber int) bool {
+	for _, def := range items {
+		if def.OnLevel(levelNumber) && slices.Contains(def.Effects, "shield") {
+			return true
+		}
+	}
+	return false
+}
+
+// pelletCount returns the number of pellets a maze starts with, counting the
+// built-in maze for an empty one
+func pelletCount(maze []string) int {
+	if len(maze) == 0 {
+		maze = DefaultMaze
+	}
+	count := 0
+	for _, row := range maze {
+		count += strings.Count(row, ".") + strings.Count(row, "^")
+	}
+	return count
+}
@@ -0,0 +1,486 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Rules holds the data-driven gameplay constants and difficulty presets
+type Rules struct {
+	TickRate         int                      `json:"tick_rate"`          // simulation ticks per second
+	GameSpeed        float64                  `json:"game_speed"`         // simulated seconds per real second
+	Seed             int64                    `json:"seed"`               // random seed; 0 picks a new one each game outside time attack
+	PlayerSpeed      float64                  `json:"player_speed"`       // tiles per second
+	Lives            int                      `json:"lives"`              // lives at the start of a game
+	DeathTime        float64                  `json:"death_time"`         // seconds the death sequence plays
+	NearMissRadius   float64                  `json:"near_miss_radius"`   // pixels
+	NearMissCooldown float64                  `json:"near_miss_cooldown"` // seconds before another near-miss is counted
+	PlayerCornering  float64                  `json:"player_cornering"`   // pixels around a tile center the player may turn
+	GhostCornering   float64                  `json:"ghost_cornering"`    // pixels around a tile center ghosts may turn
+	TurnBufferTime   float64                  `json:"turn_buffer_time"`   // seconds a released turn stays queued
+	GhostAvoidance   bool                     `json:"ghost_avoidance"`    // ghosts steer around tiles held by other ghosts
+	AvoidanceCost    int                      `json:"avoidance_cost"`     // extra tiles of distance for a held tile
+	Controls         map[string][]string      `json:"controls"`           // action name to key names
+	Colliders        Colliders                `json:"colliders"`
+	Momentum         MomentumConfig           `json:"momentum"` // player handling in momentum movement mode
+	Scoring          ScoringRules             `json:"scoring"`
+	Items            map[string]ItemDef       `json:"items"` // pickups by ID; an entry replaces that item's defaults
+	Spawn            SpawnRules               `json:"spawn"`
+	PowerUps         map[string]PowerUpConfig `json:"power_ups"` // tuning per effect; an entry replaces that effect's defaults
+	Adaptive         AdaptiveBounds           `json:"adaptive"`
+	Elroy            []ElroyRule              `json:"elroy"`  // per level; the last entry applies to all later levels
+	Levels           []LevelRules             `json:"levels"` // per level; the last entry applies to all later levels
+	Survival         SurvivalRules            `json:"survival"`
+	Difficulties     []DifficultyConfig       `json:"difficulties"`
+}
+
+// ScoringRules sets the points for each scoring event and when extra lives are awarded
+type ScoringRules struct {
+	Pellet         int   `json:"pellet"`
+	PowerPellet    int   `json:"power_pellet"`     // for starting a ghost-eating phase, such as picking up a shield
+	GhostChain     []int `json:"ghost_chain"`      // points per ghost eaten during one power-up; the last value repeats
+	ExtraLifeAt    []int `json:"extra_life_at"`    // ascending scores that award an extra life
+	ExtraLifeEvery int   `json:"extra_life_every"` // after the last threshold, another life every this many points; 0 for none
+	Second         int   `json:"second"`           // points per second survived in survival mode
+}
+
+// PowerUps lists the timed effects a pickup may grant, sorted. The built-in
+// effects are always known, so rules validate the same without the powerup
+// package; effect types it registers later are added.
+var PowerUps = []string{"freeze", "invisibility", "magnet", "phasing", "shield", "speed"}
+
+// RegisterPowerUp makes an effect name valid in the rules
+func RegisterPowerUp(name string) {
+	if !slices.Contains(PowerUps, name) {
+		PowerUps = append(PowerUps, name)
+		slices.Sort(PowerUps)
+	}
+}
+
+// StackModes lists what granting an effect that is already active may do:
+// "refresh" restarts its timer and "stack" adds the new time to what is left
+var StackModes = []string{"refresh", "stack"}
+
+// PowerUpConfig tunes one power-up effect
+type PowerUpConfig struct {
+	Time     float64 `json:"time"`     // seconds the effect lasts
+	Strength float64 `json:"strength"` // speed multiplier for speed, radius in tiles for magnet; unused otherwise
+	Stacking string  `json:"stacking"` // one of StackModes
+}
+
+// MomentumConfig tunes the player's acceleration in momentum movement mode,
+// where PlayerSpeed becomes the top speed
+type MomentumConfig struct {
+	Acceleration  float64 `json:"acceleration"`    // tiles per second gained each second while a direction is held
+	Friction      float64 `json:"friction"`        // tiles per second lost each second while no direction is held
+	TurnSpeedKept float64 `json:"turn_speed_kept"` // share of the speed kept through a turn
+}
+
+// ElroyRule configures when the lead ghost speeds up as pellets run out
+type ElroyRule struct {
+	Stage1Pellets int     `json:"stage1_pellets"` // remaining pellets that start the first stage
+	Stage1Speed   float64 `json:"stage1_speed"`   // lead ghost speed multiplier in the first stage
+	Stage2Pellets int     `json:"stage2_pellets"` // remaining pellets that start the second stage
+	Stage2Speed   float64 `json:"stage2_speed"`   // lead ghost speed multiplier in the second stage
+}
+
+// DefaultRules returns the built-in rules used when no rules file is present
+func DefaultRules() *Rules {
+	return &Rules{
+		TickRate:         60,
+		GameSpeed:        1,
+		PlayerSpeed:      5.5,
+		Lives:            3,
+		DeathTime:        1.5,
+		NearMissRadius:   24.0,
+		NearMissCooldown: 1,
+		PlayerCornering:  6,
+		GhostCornering:   0,
+		TurnBufferTime:   0.13,
+		GhostAvoidance:   true,
+		AvoidanceCost:    4,
+		Controls: map[string][]string{
+			"up":      {"ArrowUp", "W"},
+			"down":    {"ArrowDown", "S"},
+			"left":    {"ArrowLeft", "A"},
+			"right":   {"ArrowRight", "D"},
+			"restart": {"R"},
+			"debug":   {"F1"},
+			"back":    {"Escape"},
+			"faster":  {"Equal"},
+			"slower":  {"Minus"},
+			"undo":    {"Z", "Backspace"},
+			"next":    {"Enter", "Space"},
+		},
+		Colliders: Colliders{
+			Player: ColliderConfig{Mode: CollideCircle, Size: 4, Body: 9.6},
+			Ghost:  ColliderConfig{Mode: CollideCircle, Size: 4, Body: 9.6},
+		},
+		Scoring: ScoringRules{
+			Pellet:      10,
+			PowerPellet: 50,
+			GhostChain:  []int{200, 400, 800, 1600},
+			ExtraLifeAt: []int{1500},
+			Second:      10,
+		},
+		Items:    defaultItems(),
+		Levels:   defaultLevels(),
+		Survival: defaultSurvival(),
+		// Items keep their distance from the player unless the rules say otherwise
+		Spawn: SpawnRules{
+			Levels:      []string{"distant"},
+			Modes:       map[string]string{},
+			MinDistance: 5,
+		},
+		PowerUps: map[string]PowerUpConfig{
+			"speed":        {Time: 5, Strength: 1.8, Stacking: "refresh"},
+			"freeze":       {Time: 3, Stacking: "refresh"},
+			"invisibility": {Time: 5, Stacking: "refresh"},
+			"magnet":       {Time: 8, Strength: 2.5, Stacking: "stack"},
+			"phasing":      {Time: 4, Stacking: "refresh"},
+			"shield":       {Time: 15, Stacking: "stack"},
+		},
+		Momentum: MomentumConfig{
+			Acceleration:  8,
+			Friction:      6,
+			TurnSpeedKept: 0.6,
+		},
+		Adaptive: AdaptiveBounds{
+			MinSpeedScale:     0.8,
+			MaxSpeedScale:     1.25,
+			MaxTierShift:      1,
+			WindowTime:        10,
+			TargetPelletRate:  1.5,
+			LongSurvivalTime:  30,
+			IntensityStep:     0.25,
+			NearMissTolerance: 3,
+		},
+		Elroy: []ElroyRule{
+			{Stage1Pellets: 20, Stage1Speed: 1.1, Stage2Pellets: 10, Stage2Speed: 1.2},
+			{Stage1Pellets: 30, Stage1Speed: 1.15, Stage2Pellets: 15, Stage2Speed: 1.25},
+		},
+		Difficulties: []DifficultyConfig{
+			{
+				Name:        "Easy",
+				Description: "Ghosts are slow and not very smart",
+				GhostSpeeds: []float64{2.5, 2.75, 2.5, 2.25},
+				SkillLevels: []GhostLevel{
+					GhostSkillLevelDumb, // Blinky: Random movement
+					GhostSkillLevelSlow, // Pinky: Makes mistakes
+					GhostSkillLevelDumb, // Inky: Random movement
+					GhostSkillLevelSlow, // Clyde: Makes mistakes
+				},
+				// Mostly random and patrol, one chase
+				Algorithms:  []string{"Random", "Patrol", "Chase", "Frightened"},
+				RecalcEvery: 12, // Slower rate
+			},
+			{
+				Name:        "Medium",
+				Description: "Balanced gameplay with mixed ghost abilities",
+				GhostSpeeds: []float64{3.25, 3.5, 3.0, 3.25}, // Medium speeds
+				SkillLevels: []GhostLevel{
+					GhostSkillLevelNormal, // Blinky: Standard intelligence
+					GhostSkillLevelSlow,   // Pinky: Makes some mistakes
+					GhostSkillLevelNormal, // Inky: Standard intelligence
+					GhostSkillLevelSlow,   // Clyde: Makes some mistakes
+				},
+				// Mix of chase, scatter, and patrol
+				Algorithms:  []string{"Chase", "Scatter", "Patrol", "Ambush"},
+				RecalcEvery: 8, // Medium update rate
+			},
+			{
+				Name:        "Hard",
+				Description: "Fast and intelligent ghosts",
+				GhostSpeeds: []float64{3.75, 4.0, 3.5, 3.75}, // Faster ghosts
+				SkillLevels: []GhostLevel{
+					GhostSkillLevelSmart,  // Blinky: Smart intelligence
+					GhostSkillLevelNormal, // Pinky: Standard intelligence
+					GhostSkillLevelSmart,  // Inky: Smart intelligence
+					GhostSkillLevelNormal, // Clyde: Standard intelligence
+				},
+				// Mostly chase and ambush, one scatter
+				Algorithms:  []string{"Chase", "Ambush", "Chase", "Scatter"},
+				RecalcEvery: 6, // Standard update rate
+			},
+			{
+				Name:        "Genius",
+				Description: "Ghosts anticipate your every move",
+				GhostSpeeds: []float64{3.75, 4.0, 3.75, 3.75},
+				SkillLevels: []GhostLevel{
+					GhostSkillLevelGenius, // Blinky: Genius intelligence
+					GhostSkillLevelGenius, // Pinky: Genius intelligence
+					GhostSkillLevelSmart,  // Inky: Smart intelligence
+					GhostSkillLevelGenius, // Clyde: Genius intelligence
+				},
+				// Chasers cut off escape routes from both sides
+				Algorithms:  []string{"Chase", "Ambush", "Chase", "Ambush"},
+				RecalcEvery: 4, // Fastest update rate
+			},
+		},
+	}
+}
+
+// LoadRules reads a JSON rules file on top of the built-in defaults. A missing
+// file is not an error. On any error the built-in defaults are returned along
+// with it, so callers can log the error and carry on.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultRules(), nil
+	}
+	if err != nil {
+		return DefaultRules(), fmt.Errorf("read rules: %w", err)
+	}
+
+	// Omitted fields keep their defaults, but a listed array replaces the
+	// built-in one entirely
+	rules := DefaultRules()
+	defaults := DefaultRules()
+	rules.Difficulties = nil
+	rules.Elroy = nil
+	rules.Levels = nil
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rules); err != nil {
+		return DefaultRules(), fmt.Errorf("parse rules %s: %w", path, err)
+	}
+	if rules.Difficulties == nil {
+		rules.Difficulties = defaults.Difficulties
+	}
+	if rules.Elroy == nil {
+		rules.Elroy = defaults.Elroy
+	}
+	if rules.Levels == nil {
+		rules.Levels = defaults.Levels
+	}
+
+	if err := rules.Validate(); err != nil {
+		return DefaultRules(), fmt.Errorf("invalid rules %s:\n%w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Validate checks the rules for values the game cannot work with and reports
+// every problem found
+func (r *Rules) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if r.TickRate < 10 || r.TickRate > 480 {
+		fail("tick_rate must be in [10, 480], got %d", r.TickRate)
+	}
+	if r.GameSpeed < 0.5 || r.GameSpeed > 3 {
+		fail("game_speed must be in [0.5, 3], got %v", r.GameSpeed)
+	}
+	if r.PlayerSpeed <= 0 {
+		fail("player_speed must be positive, got %v", r.PlayerSpeed)
+	}
+	if r.Lives < 1 {
+		fail("lives must be at least 1, got %d", r.Lives)
+	}
+	if r.DeathTime < 0 {
+		fail("death_time must not be negative, got %v", r.DeathTime)
+	}
+	validateScoring(r.Scoring, fail)
+	validateItems(r.Items, r.PowerUps, fail)
+	validateSpawn(r.Spawn, fail)
+	validateLevels(r.Levels, r.Items, fail)
+	validateSurvival(r.Survival, fail)
+	validatePowerUps(r.PowerUps, fail)
+	if r.NearMissRadius <= 0 {
+		fail("near_miss_radius must be positive, got %v", r.NearMissRadius)
+	}
+	validateCollider("colliders.player", r.Colliders.Player, true, fail)
+	validateCollider("colliders.ghost", r.Colliders.Ghost, true, fail)
+	if r.Momentum.Acceleration <= 0 {
+		fail("momentum.acceleration must be positive, got %v", r.Momentum.Acceleration)
+	}
+	if r.Momentum.Friction < 0 {
+		fail("momentum.friction must not be negative, got %v", r.Momentum.Friction)
+	}
+	if r.Momentum.TurnSpeedKept < 0 || r.Momentum.TurnSpeedKept > 1 {
+		fail("momentum.turn_speed_kept must be in [0, 1], got %v", r.Momentum.TurnSpeedKept)
+	}
+	if r.NearMissCooldown < 0 {
+		fail("near_miss_cooldown must not be negative, got %v", r.NearMissCooldown)
+	}
+	// Cornering must stay within the tile, which is 24 pixels wide
+	if r.PlayerCornering < 0 || r.PlayerCornering >= 12 {
+		fail("player_cornering must be in [0, 12), got %v", r.PlayerCornering)
+	}
+	if r.GhostCornering < 0 || r.GhostCornering >= 12 {
+		fail("ghost_cornering must be in [0, 12), got %v", r.GhostCornering)
+	}
+	if r.TurnBufferTime < 0 {
+		fail("turn_buffer_time must not be negative, got %v", r.TurnBufferTime)
+	}
+	if r.AvoidanceCost < 0 {
+		fail("avoidance_cost must not be negative, got %v", r.AvoidanceCost)
+	}
+
+	a := r.Adaptive
+	if a.MinSpeedScale <= 0 || a.MinSpeedScale > 1 {
+		fail("adaptive.min_speed_scale must be in (0, 1], got %v", a.MinSpeedScale)
+	}
+	if a.MaxSpeedScale < 1 {
+		fail("adaptive.max_speed_scale must be at least 1, got %v", a.MaxSpeedScale)
+	}
+	if a.MaxTierShift < 0 {
+		fail("adaptive.max_tier_shift must not be negative, got %d", a.MaxTierShift)
+	}
+	if a.WindowTime <= 0 {
+		fail("adaptive.window_time must be positive, got %v", a.WindowTime)
+	}
+	if a.TargetPelletRate <= 0 {
+		fail("adaptive.target_pellet_rate must be positive, got %v", a.TargetPelletRate)
+	}
+	if a.LongSurvivalTime <= 0 {
+		fail("adaptive.long_survival_time must be positive, got %v", a.LongSurvivalTime)
+	}
+	if a.IntensityStep <= 0 || a.IntensityStep > 1 {
+		fail("adaptive.intensity_step must be in (0, 1], got %v", a.IntensityStep)
+	}
+	if a.NearMissTolerance < 0 {
+		fail("adaptive.near_miss_tolerance must not be negative, got %d", a.NearMissTolerance)
+	}
+
+	if len(r.Elroy) == 0 {
+		fail("elroy must contain at least one rule")
+	}
+	for i, e := range r.Elroy {
+		if e.Stage2Pellets < 0 || e.Stage1Pellets < e.Stage2Pellets {
+			fail("elroy[%d]: pellet thresholds must satisfy 0 <= stage2_pellets <= stage1_pellets, got %d and %d", i, e.Stage2Pellets, e.Stage1Pellets)
+		}
+		if e.Stage1Speed <= 0 || e.Stage2Speed <= 0 {
+			fail("elroy[%d]: speed multipliers must be positive, got %v and %v", i, e.Stage1Speed, e.Stage2Speed)
+		}
+	}
+
+	if len(r.Difficulties) == 0 {
+		fail("difficulties must contain at least one preset")
+	}
+	seen := make(map[string]bool)
+	for i, d := range r.Difficulties {
+		where := fmt.Sprintf("difficulties[%d]", i)
+		if d.Name == "" {
+			fail("%s: name must not be empty", where)
+		} else {
+			where = fmt.Sprintf("%s (%q)", where, d.Name)
+			if seen[d.Name] {
+				fail("%s: duplicate preset name", where)
+			}
+			seen[d.Name] = true
+		}
+
+		if len(d.GhostSpeeds) == 0 {
+			fail("%s: ghost_speeds must list at least one ghost", where)
+		}
+		for j, speed := range d.GhostSpeeds {
+			if speed <= 0 {
+				fail("%s: ghost_speeds[%d] must be positive, got %v", where, j, speed)
+			}
+		}
+		if len(d.SkillLevels) != len(d.GhostSpeeds) {
+			fail("%s: skill_levels has %d entries, want one per ghost (%d)", where, len(d.SkillLevels), len(d.GhostSpeeds))
+		}
+		if len(d.Algorithms) != len(d.GhostSpeeds) {
+			fail("%s: algorithms has %d entries, want one per ghost (%d)", where, len(d.Algorithms), len(d.GhostSpeeds))
+		}
+		for j, name := range d.Algorithms {
+			if !slices.Contains(GhostAlgorithms, name) {
+				fail("%s: algorithms[%d] %q is not one of %v", where, j, name, GhostAlgorithms)
+			}
+		}
+		if d.RecalcEvery < 1 {
+			fail("%s: recalc_every must be at least 1, got %d", where, d.RecalcEvery)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Preset returns the difficulty preset at the given index, falling back to the first one
+func (r *Rules) Preset(difficulty Difficulty) DifficultyConfig {
+	if int(difficulty) < 0 || int(difficulty) >= len(r.Difficulties) {
+		return r.Difficulties[0]
+	}
+	return r.Difficulties[difficulty]
+}
+
+// ElroyFor returns the Cruise Elroy rule for a 1-based level number
+func (r *Rules) ElroyFor(levelNumber int) ElroyRule {
+	i := min(max(levelNumber-1, 0), len(r.Elroy)-1)
+	return r.Elroy[i]
+}
+
+// PresetNames returns the names of all difficulty presets in menu order
+func (r *Rules) PresetNames() []string {
+	names := make([]string, len(r.Difficulties))
+	for i, d := range r.Difficulties {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// validateScoring checks that points are not negative and extra lives come at rising scores
+func validateScoring(s ScoringRules, fail func(string, ...any)) {
+	if s.Pellet < 0 || s.PowerPellet < 0 || s.Second < 0 {
+		fail("scoring: pellet, power_pellet and second must not be negative, got %d, %d and %d", s.Pellet, s.PowerPellet, s.Second)
+	}
+	if len(s.GhostChain) == 0 {
+		fail("scoring.ghost_chain must not be empty")
+	}
+	for i, points := range s.GhostChain {
+		if points < 0 {
+			fail("scoring.ghost_chain[%d] must not be negative, got %d", i, points)
+		}
+	}
+	for i, score := range s.ExtraLifeAt {
+		if score <= 0 || (i > 0 && score <= s.ExtraLifeAt[i-1]) {
+			fail("scoring.extra_life_at must be positive and ascending, got %v", s.ExtraLifeAt)
+			break
+		}
+	}
+	if s.ExtraLifeEvery < 0 {
+		fail("scoring.extra_life_every must not be negative, got %d", s.ExtraLifeEvery)
+	}
+}
+
+// validatePowerUps checks that every tuned power-up is a known effect with
+// usable values
+func validatePowerUps(powerUps map[string]PowerUpConfig, fail func(string, ...any)) {
+	for name, p := range powerUps {
+		if !slices.Contains(PowerUps, name) {
+			fail("power_ups: unknown effect %q, want one of %v", name, PowerUps)
+			continue
+		}
+		if p.Time <= 0 {
+			fail("power_ups.%s.time must be positive, got %v", name, p.Time)
+		}
+		if !slices.Contains(StackModes, p.Stacking) {
+			fail("power_ups.%s.stacking: unknown mode %q, want one of %v", name, p.Stacking, StackModes)
+		}
+		if (name == "speed" || name == "magnet") && p.Strength <= 0 {
+			fail("power_ups.%s.strength must be positive, got %v", name, p.Strength)
+		}
+	}
+}
+
+// validateCollider checks a collider's sizes; moving entities also need a body
+// that fits inside a tile, which is 24 pixels wide
+func validateCollider(name string, c ColliderConfig, moves bool, fail func(string, ...any)) {
+	if c.Mode != CollideTile && c.Size <= 0 {
+		fail("%s.size must be positive for %s, got %v", name, c.Mode, c.Size)
+	}
+	if moves && (c.Body <= 0 || c.Body >= 12) {
+		fail("%s.body must be in (0, 12), got %v", name, c.Body)
+	}
+}
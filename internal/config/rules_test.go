@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// The config package is tested without the powerup and spawn packages, so
+// nothing here has been registered by them
+
+func TestDefaultRulesValidate(t *testing.T) {
+	if err := DefaultRules().Validate(); err != nil {
+		t.Fatalf("default rules: %v", err)
+	}
+}
+
+func TestValidateRejectsUnknownNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *Rules)
+		want   string
+	}{
+		{
+			name:   "power-up tuning",
+			modify: func(r *Rules) { r.PowerUps["teleport"] = PowerUpConfig{Time: 5} },
+			want:   `unknown effect "teleport"`,
+		},
+		{
+			name: "item effect",
+			modify: func(r *Rules) {
+				def := r.Items["cherry"]
+				def.Effects = []string{"teleport"}
+				r.Items["cherry"] = def
+			},
+			want: `"teleport"`,
+		},
+		{
+			name:   "spawn strategy",
+			modify: func(r *Rules) { r.Spawn.Levels = []string{"corner"} },
+			want:   `unknown strategy "corner"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DefaultRules()
+			tt.modify(r)
+			err := r.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want an error mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
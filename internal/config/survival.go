@@ -0,0 +1,55 @@
+package config
+
+import "slices"
+
+// SurvivalRules tunes the endless survival mode, where pellets come back in
+// waves and every wave makes the ghosts more dangerous
+type SurvivalRules struct {
+	WaveTime      float64  `json:"wave_time"`       // seconds between waves; clearing the pellets starts the next one early
+	StartGhosts   int      `json:"start_ghosts"`    // ghosts on the board when play starts
+	MaxGhosts     int      `json:"max_ghosts"`      // once reached, waves only upgrade ghosts
+	Ladder        []string `json:"ladder"`          // algorithms a ghost is upgraded through, weakest first
+	SpeedRamp     float64  `json:"speed_ramp"`      // ghost speed multiplier gained per minute survived
+	MaxSpeedScale float64  `json:"max_speed_scale"` // cap on the ramped ghost speed multiplier
+}
+
+// SpeedScale returns the ghost speed multiplier after surviving the given seconds
+func (s SurvivalRules) SpeedScale(seconds float64) float64 {
+	return min(1+s.SpeedRamp*seconds/60, s.MaxSpeedScale)
+}
+
+func defaultSurvival() SurvivalRules {
+	return SurvivalRules{
+		WaveTime:      30,
+		StartGhosts:   1,
+		MaxGhosts:     8,
+		Ladder:        []string{"Random", "Patrol", "Chase", "Ambush"},
+		SpeedRamp:     0.1,
+		MaxSpeedScale: 1.6,
+	}
+}
+
+// validateSurvival checks that waves come, ghosts have somewhere to start and
+// the upgrade ladder names known algorithms
+func validateSurvival(s SurvivalRules, fail func(string, ...any)) {
+	if s.WaveTime <= 0 {
+		fail("survival.wave_time must be positive, got %v", s.WaveTime)
+	}
+	if s.StartGhosts < 1 || s.StartGhosts > s.MaxGhosts {
+		fail("survival: start_ghosts must be in [1, max_ghosts (%d)], got %d", s.MaxGhosts, s.StartGhosts)
+	}
+	if len(s.Ladder) == 0 {
+		fail("survival.ladder must contain at least one algorithm")
+	}
+	for i, name := range s.Ladder {
+		if !slices.Contains(GhostAlgorithms, name) {
+			fail("survival.ladder[%d] %q is not one of %v", i, name, GhostAlgorithms)
+		}
+	}
+	if s.SpeedRamp < 0 {
+		fail("survival.speed_ramp must not be negative, got %v", s.SpeedRamp)
+	}
+	if s.MaxSpeedScale < 1 {
+		fail("survival.max_speed_scale must be at least 1, got %v", s.MaxSpeedScale)
+	}
+}
@@ -2,15 +2,23 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
+	"log"
+	"math/rand/v2"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/input"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/adaptive"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/clock"
 	"github.com/vladyslavpavlenko/pacman/internal/logic/intelligence"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/objective"
 	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/powerup"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/scoring"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/spawn"
 	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/records"
 	"github.com/vladyslavpavlenko/pacman/internal/types"
 	"github.com/vladyslavpavlenko/pacman/internal/view"
 	"github.com/vladyslavpavlenko/pacman/internal/view/renderer"
@@ -18,14 +26,7 @@ import (
 )
 
 const (
-	PlayerSpeed          = 2.2 // pixels per frame
-	GhostSpeed           = 1.4 // pixels per frame
-	RecalcEvery          = 6   // frames between BFS recalcs
-	CatchRadius          = 8.0 // pixels
-	ScreenScale          = 1
-	AppleRadius          = 6.0 // pixels
-	SpeedBoostTime       = 300 // frames (5 seconds at 60fps)
-	SpeedBoostMultiplier = 1.8
+	ScreenScale = 1
 )
 
 // Game represents the main game state
@@ -33,167 +34,266 @@ type Game struct {
 	level            *model.Level
 	player           *model.Player
 	ghosts           []*model.Ghost
-	score            int
+	scorer           *scoring.Scorer
 	pelletsCollected int
 	finalScore       int
-	frame            int
+	tick             int
 	distMap          *intelligence.DistanceMap
 	renderer         *renderer.Renderer
+	rules            *config.Rules
 	difficulty       config.Difficulty
+	diffConfig       config.DifficultyConfig
+	levelNumber      int
+	levelRules       config.LevelRules
+	levelTicks       int                // ticks played in the level, for timed objectives
+	objectives       []objective.Status // as of the last check
+	ghostsEaten      int
+	fruitCollected   int
+	fruitMissed      int // history items that vanished without coming back
+	reachedExit      bool
 	recalcEvery      int
 	menu             *ui.UI
 	gameState        view.State
 	shouldExit       bool
-	speedBoostFrames int
 	basePlayerSpeed  float64
+	powerups         *powerup.Manager
 	debugMode        bool
 	ghostAlgorithms  []string
+	ghostBaseSpeeds  []float64
+	adaptiveEnabled  bool
+	momentumEnabled  bool
+	replayEnabled    bool       // draw the best run's replay in time attack
+	seed             int64      // random seed of the current game
+	rng              *rand.Rand // every random choice of the level, seeded with seed
+	rngState         *rand.PCG  // rng's source, copied by value to save and restore it
+	mode             config.GameMode
+	turns            *turnState  // nil outside turn-based mode
+	timeAttack       *timeAttack // nil outside time-attack mode
+	survival         *survival   // nil outside survival mode
+	records          *records.Store
+	lives            int
+	itemsSpawned     map[string]int // copies of each item already spawned this level
+	recentItems      []string       // sprites of the most recently collected items, oldest first
+	respawns         []itemRespawn  // collected or vanished items waiting to appear again
+	spawner          *spawn.Spawner
+	dyingTicks       int // ticks left in the death sequence; 0 when alive
+	adaptive         *adaptive.Director
+	nearMissCooldown int
+	controls         *input.Controller
+	turnBuffer       *input.TurnBuffer
+	clock            *clock.Clock
 }
 
-// New creates a new game instance
-func New() *Game {
+// New creates a new game instance using the given gameplay rules
+func New(rules *config.Rules, store *records.Store) *Game {
+	keymap, err := input.NewKeymap(rules.Controls)
+	if err != nil {
+		log.Printf("%v\nusing default controls", err)
+		keymap, _ = input.NewKeymap(config.DefaultRules().Controls)
+	}
+
+	simClock := clock.New(rules.TickRate, rules.GameSpeed)
+
 	return &Game{
-		renderer:   renderer.New(),
-		menu:       ui.New(),
+		rules:      rules,
+		renderer:   renderer.New(rules.TickRate),
+		menu:       ui.New(rules.PresetNames(), keymap),
 		gameState:  view.StateMenu,
 		shouldExit: false,
+		controls:   input.NewController(keymap),
+		turnBuffer: input.NewTurnBuffer(simClock.Ticks(rules.TurnBufferTime)),
+		clock:      simClock,
+		records:    store,
+	}
+}
+
+// speedPerTick converts a speed in tiles per second to sub-pixels per simulation tick
+func (g *Game) speedPerTick(tilesPerSecond float64) types.Fixed {
+	return physics.SpeedPerTick(tilesPerSecond, g.clock.TickRate())
+}
+
+// newMomentum converts the configured momentum handling to per-tick values
+func (g *Game) newMomentum() *model.Momentum {
+	tickRate := g.clock.TickRate()
+	return &model.Momentum{
+		Accel:    physics.AccelPerTick(g.rules.Momentum.Acceleration, tickRate),
+		Friction: physics.AccelPerTick(g.rules.Momentum.Friction, tickRate),
+		TurnKeep: types.FixedFromFloat(g.rules.Momentum.TurnSpeedKept),
+	}
+}
+
+// readInput polls the keyboard once per frame and queues the requested direction
+func (g *Game) readInput() {
+	g.controls.Update()
+
+	if dir, ok := g.controls.Direction(); ok {
+		g.turnBuffer.Queue(dir)
+	}
+}
+
+// steerPlayer keeps offering the queued direction to the player until it
+// fires at a junction or the turn buffer runs out
+func (g *Game) steerPlayer() {
+	if m := g.player.Momentum; m != nil {
+		_, held := g.controls.Direction()
+		m.Coasting = !held
 	}
+
+	want, ok := g.turnBuffer.Next()
+	if !ok {
+		// Drop an expired turn so it doesn't fire at a later junction
+		g.player.WantDir = g.player.Dir
+		return
+	}
+
+	physics.TryTurn(&g.player.Entity, want, g.level)
 }
 
-// consumePellet checks if player is on a pellet and consumes it
+// consumePellet consumes the pellet under the player, and with a magnet
+// every pellet within its radius
 func (g *Game) consumePellet() {
 	tileX, tileY := physics.PosToTile(g.player.Pos)
-	if g.level.ConsumePellet(tileX, tileY) {
-		g.score++
-		g.pelletsCollected++
+	radius := g.powerups.Strength(powerup.Magnet)
+	reach := int(radius)
+
+	for y := tileY - reach; y <= tileY+reach; y++ {
+		for x := tileX - reach; x <= tileX+reach; x++ {
+			dx, dy := float64(x-tileX), float64(y-tileY)
+			if dx*dx+dy*dy <= radius*radius {
+				g.eatPellet(x, y)
+			}
+		}
 	}
 }
 
-// checkAppleCollection checks if player collected any apples
-func (g *Game) checkAppleCollection() {
-	for i := len(g.level.Apples) - 1; i >= 0; i-- {
-		apple := g.level.Apples[i]
-		if physics.CheckCollision(&g.player.Entity, &apple.Entity, AppleRadius) {
-			// Remove apple from level
-			g.level.RemoveApple(apple)
-			// Add score
-			g.score++
-			// Apply speed boost
-			g.applySpeedBoost()
+// eatPellet consumes the pellet on the given tile, if there is one
+func (g *Game) eatPellet(tileX, tileY int) {
+	if g.level.ConsumePellet(tileX, tileY) {
+		g.award(g.scorer.Pellet())
+		g.pelletsCollected++
+		if g.adaptive != nil {
+			g.adaptive.RecordPellet()
 		}
 	}
 }
 
-// applySpeedBoost applies a temporary speed boost to the player
-func (g *Game) applySpeedBoost() {
-	g.speedBoostFrames = SpeedBoostTime
-	g.player.Speed = g.basePlayerSpeed * SpeedBoostMultiplier
+// visitTile tells the item spawner which tile the player is on
+func (g *Game) visitTile() {
+	tileX, tileY := physics.PosToTile(g.player.Pos)
+	g.spawner.Visit(types.Tile{X: tileX, Y: tileY})
 }
 
-// updateSpeedBoost updates the speed boost timer
-func (g *Game) updateSpeedBoost() {
-	if g.speedBoostFrames > 0 {
-		g.speedBoostFrames--
-		if g.speedBoostFrames == 0 {
-			g.player.Speed = g.basePlayerSpeed
-		}
+// award applies the side effects of points scored, such as extra lives
+func (g *Game) award(a scoring.Award) {
+	// Turn-based play has undo instead of lives
+	if g.turns == nil {
+		g.lives += a.ExtraLives
 	}
 }
 
 // resetPositions resets all entities to their spawn positions
 func (g *Game) resetPositions() {
+	g.turnBuffer.Clear()
 	physics.ResetEntityPosition(&g.player.Entity)
 	for _, ghost := range g.ghosts {
-		physics.ResetEntityPosition(&ghost.Entity)
+		physics.ResetGhost(ghost)
 	}
 }
 
-// resetLevel resets the level to its original state (restores pellets)
-func (g *Game) resetLevel() {
-	// Reset the level to original state
-	g.level = model.New(nil)
-
-	// Reset counters
-	g.score = 0
-	g.pelletsCollected = 0
-	g.speedBoostFrames = 0
-	g.basePlayerSpeed = PlayerSpeed
-
-	// Reset player speed
-	g.player.Speed = g.basePlayerSpeed
+// loseLife takes a life from the player and starts the death sequence
+func (g *Game) loseLife() {
+	g.lives--
+	g.powerups.Clear()
+	g.applyPowerUps()
 
-	// Respawn apples
-	g.spawnApples()
+	g.dyingTicks = g.clock.Ticks(g.rules.DeathTime)
+	if g.dyingTicks == 0 {
+		g.finishDeath()
+	}
+}
 
-	// Reset positions
+// finishDeath ends the death sequence, either respawning everyone with the
+// eaten pellets kept or ending the game when no lives are left
+func (g *Game) finishDeath() {
+	g.dyingTicks = 0
+	if g.lives <= 0 {
+		g.finalScore = g.scorer.Score()
+		g.gameState = view.StateGameOver
+		return
+	}
 	g.resetPositions()
 }
 
+// deathProgress returns how far the death sequence has played, from 0 to 1
+func (g *Game) deathProgress() float64 {
+	total := g.clock.Ticks(g.rules.DeathTime)
+	if total == 0 {
+		return 1
+	}
+	return 1 - float64(g.dyingTicks)/float64(total)
+}
+
 // updateGhostAI updates a ghost's AI based on the algorithm name
 func (g *Game) updateGhostAI(ghost *model.Ghost, algorithmName string) {
 	// Define corner positions for scatter behavior
-	corners := []types.Vector{
-		{X: 1, Y: 1},                                                    // Top-left
-		{X: float64(g.level.Width - 2), Y: 1},                           // Top-right
-		{X: 1, Y: float64(g.level.Height - 2)},                          // Bottom-left
-		{X: float64(g.level.Width - 2), Y: float64(g.level.Height - 2)}, // Bottom-right
+	corners := []types.Tile{
+		{X: 1, Y: 1},                                  // Top-left
+		{X: g.level.Width - 2, Y: 1},                  // Top-right
+		{X: 1, Y: g.level.Height - 2},                 // Bottom-left
+		{X: g.level.Width - 2, Y: g.level.Height - 2}, // Bottom-right
 	}
 
 	// Define patrol points
-	patrolPoints := []types.Vector{
-		{X: float64(g.level.Width / 4), Y: float64(g.level.Height / 4)},
-		{X: float64(3 * g.level.Width / 4), Y: float64(3 * g.level.Height / 4)},
+	patrolPoints := []types.Tile{
+		{X: g.level.Width / 4, Y: g.level.Height / 4},
+		{X: 3 * g.level.Width / 4, Y: 3 * g.level.Height / 4},
 	}
 
 	switch algorithmName {
 	case "Chase":
-		intelligence.ChaseAI(&ghost.Entity, g.distMap, g.level, g.player.Pos)
+		intelligence.ChaseAI(ghost, g.distMap, g.level, g.rng, g.player.Pos)
 	case "Scatter":
 		// Use different corners for different ghosts
 		cornerIndex := len(g.ghosts) % len(corners)
-		intelligence.ScatterAI(&ghost.Entity, g.distMap, g.level, corners[cornerIndex])
+		intelligence.ScatterAI(ghost, g.distMap, g.level, g.rng, corners[cornerIndex])
 	case "Frightened":
-		intelligence.FrightenedAI(&ghost.Entity, g.distMap, g.level)
+		intelligence.FrightenedAI(ghost, g.distMap, g.level, g.rng)
 	case "Patrol":
-		intelligence.PatrolAI(&ghost.Entity, g.distMap, g.level, patrolPoints)
+		intelligence.PatrolAI(ghost, g.distMap, g.level, g.rng, patrolPoints)
 	case "Ambush":
-		intelligence.AmbushAI(&ghost.Entity, g.distMap, g.level, g.player.Pos, g.player.Dir)
+		intelligence.AmbushAI(ghost, g.distMap, g.level, g.rng, g.player.Pos, g.player.Dir)
 	case "Random":
-		intelligence.FrightenedAI(&ghost.Entity, g.distMap, g.level) // Use random movement
+		intelligence.FrightenedAI(ghost, g.distMap, g.level, g.rng) // Use random movement
 	default:
 		// Fallback to old AI
-		intelligence.GhostAI(&ghost.Entity, g.distMap, g.level, g.difficulty)
+		intelligence.GhostAI(ghost, g.distMap, g.level, g.rng)
 	}
 }
 
-// assignGhostAlgorithms assigns different algorithms to ghosts based on difficulty
+// assignGhostAlgorithms assigns algorithms to ghosts from the difficulty
+// preset, or in survival from each ghost's place on the ladder
 func (g *Game) assignGhostAlgorithms() {
+	previous := g.ghostAlgorithms
 	g.ghostAlgorithms = make([]string, len(g.ghosts))
 
-	switch g.difficulty {
-	case config.DifficultyEasy:
-		// Easy: Mostly random and patrol, one chase
-		algorithms := []string{"Random", "Patrol", "Chase", "Frightened"}
-		for i := range g.ghosts {
-			g.ghostAlgorithms[i] = algorithms[i%len(algorithms)]
-		}
-	case config.DifficultyMedium:
-		// Medium: Mix of chase, scatter, and patrol
-		algorithms := []string{"Chase", "Scatter", "Patrol", "Ambush"}
-		for i := range g.ghosts {
-			g.ghostAlgorithms[i] = algorithms[i%len(algorithms)]
-		}
-	case config.DifficultyHard:
-		// Hard: Mostly chase and ambush, one scatter
-		algorithms := []string{"Chase", "Ambush", "Chase", "Scatter"}
-		for i := range g.ghosts {
-			g.ghostAlgorithms[i] = algorithms[i%len(algorithms)]
+	preset := g.diffConfig
+	if g.adaptive != nil {
+		// Adaptive mode borrows the algorithm mix of a neighbouring preset
+		shifted := int(g.difficulty) + g.adaptive.Current().TierShift
+		shifted = max(0, min(shifted, len(g.rules.Difficulties)-1))
+		preset = g.rules.Preset(config.Difficulty(shifted))
+	}
+
+	for i, ghost := range g.ghosts {
+		if g.survival != nil {
+			g.ghostAlgorithms[i] = g.survivalAlgorithm(i)
+		} else {
+			g.ghostAlgorithms[i] = preset.Algorithms[i%len(preset.Algorithms)]
 		}
-	default:
-		// Default: Random assignment
-		algorithms := []string{"Chase", "Scatter", "Patrol", "Ambush"}
-		for i := range g.ghosts {
-			g.ghostAlgorithms[i] = algorithms[i%len(algorithms)]
+
+		// A change of mode is the only time ghosts may reverse
+		if i < len(previous) && previous[i] != g.ghostAlgorithms[i] {
+			ghost.ReversePending = true
 		}
 	}
 }
@@ -201,13 +301,51 @@ func (g *Game) assignGhostAlgorithms() {
 // checkCaught checks if any ghost has caught the player
 func (g *Game) checkCaught() {
 	for _, ghost := range g.ghosts {
-		if physics.CheckCollision(&g.player.Entity, &ghost.Entity, CatchRadius) {
-			g.resetLevel() // Reset everything including pellets
+		if physics.CheckSweptCollision(&g.player.Entity, &ghost.Entity) {
+			if g.shieldBlocks(ghost) {
+				continue
+			}
+			if g.adaptive != nil {
+				g.adaptive.RecordCatch(g.tick)
+			}
+			g.loseLife()
+			return
+		}
+	}
+}
+
+// checkNearMiss records ghosts passing close to the player without catching them
+func (g *Game) checkNearMiss() {
+	if g.adaptive == nil {
+		return
+	}
+	if g.nearMissCooldown > 0 {
+		g.nearMissCooldown--
+		return
+	}
+	for _, ghost := range g.ghosts {
+		if physics.CheckCollision(&g.player.Entity, &ghost.Entity, types.FixedFromFloat(g.rules.NearMissRadius)) {
+			g.adaptive.RecordNearMiss()
+			g.nearMissCooldown = g.clock.Ticks(g.rules.NearMissCooldown)
 			return
 		}
 	}
 }
 
+// updateAdaptive lets the adaptive director re-evaluate the player and applies
+// any resulting adjustment
+func (g *Game) updateAdaptive() {
+	if g.adaptive == nil || !g.adaptive.Update(g.tick) {
+		return
+	}
+	// Ghost speeds pick the adjustment up through ghostSpeed
+	g.assignGhostAlgorithms()
+
+	adj := g.adaptive.Current()
+	log.Printf("adaptive: intensity %.2f, ghost speed x%.2f, tier shift %+d",
+		adj.Intensity, adj.SpeedScale, adj.TierShift)
+}
+
 // Update handles game logic updates
 func (g *Game) Update() error {
 	if g.gameState == view.StateMenu {
@@ -219,22 +357,28 @@ func (g *Game) Update() error {
 		if newState == view.StatePlaying {
 			g.gameState = view.StatePlaying
 			g.difficulty = selectedDiff
+			g.adaptiveEnabled = g.menu.IsAdaptive()
+			g.momentumEnabled = g.menu.IsMomentum()
+			g.replayEnabled = g.menu.IsReplay()
+			g.mode = g.menu.GetSelectedMode()
 			g.initLevel()
 		}
 		return nil
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		if g.gameState == view.StateWon {
-			g.gameState = view.StateMenu
-		} else {
-			g.gameState = view.StateMenu
-		}
+	keymap := g.controls.Keymap()
+
+	if keymap.JustPressed(input.ActionBack) {
+		g.gameState = view.StateMenu
 		return nil
 	}
 
-	if g.gameState == view.StateWon {
-		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+	if g.gameState == view.StateWon || g.gameState == view.StateGameOver || g.gameState == view.StateResults {
+		if g.gameState != view.StateGameOver && keymap.JustPressed(input.ActionNext) {
+			g.nextLevel()
+			return nil
+		}
+		if keymap.JustPressed(input.ActionRestart) {
 			g.initLevel()
 			g.gameState = view.StatePlaying
 		}
@@ -245,63 +389,108 @@ func (g *Game) Update() error {
 		return nil
 	}
 
-	g.frame++
+	g.readInput()
 
-	want := types.Vector{}
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
-		want = types.Vector{X: -1, Y: 0}
+	if keymap.JustPressed(input.ActionRestart) {
+		g.initLevel()
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
-		want = types.Vector{X: 1, Y: 0}
+
+	// Toggle debug mode
+	if keymap.JustPressed(input.ActionDebug) {
+		g.debugMode = !g.debugMode
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
-		want = types.Vector{X: 0, Y: -1}
+
+	if g.turns != nil {
+		g.updateTurns()
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
-		want = types.Vector{X: 0, Y: 1}
+
+	// Changing the game speed would make times incomparable
+	if g.timeAttack == nil {
+		if keymap.JustPressed(input.ActionFaster) {
+			g.clock.StepSpeed(true)
+		}
+		if keymap.JustPressed(input.ActionSlower) {
+			g.clock.StepSpeed(false)
+		}
 	}
-	if !want.Eq(types.Vector{}) {
-		physics.TryTurn(&g.player.Entity, want, g.level)
+
+	ticks := g.clock.Advance(time.Now())
+	for i := 0; i < ticks && g.gameState == view.StatePlaying; i++ {
+		g.step()
 	}
 
-	if g.frame%g.recalcEvery == 0 {
+	return nil
+}
+
+// step advances the simulation by one fixed tick
+func (g *Game) step() {
+	g.tick++
+	// The level clock keeps running through deaths, so times are real
+	g.levelTicks++
+
+	// Everything holds still while the death sequence plays
+	if g.dyingTicks > 0 {
+		g.dyingTicks--
+		if g.dyingTicks == 0 {
+			g.finishDeath()
+		}
+		if g.timeAttack != nil {
+			g.recordPath()
+		}
+		return
+	}
+
+	g.steerPlayer()
+
+	if g.tick%g.recalcEvery == 0 {
 		g.distMap.BuildBFS(g.player.Pos, g.level)
 	}
 
-	for i, ghost := range g.ghosts {
-		if i < len(g.ghostAlgorithms) {
-			g.updateGhostAI(ghost, g.ghostAlgorithms[i])
+	frozen := g.powerups.Has(powerup.Freeze)
+	if !frozen {
+		intelligence.ReserveTiles(g.ghosts, g.level)
+		for i, ghost := range g.ghosts {
+			if i < len(g.ghostAlgorithms) {
+				g.updateGhostAI(ghost, g.ghostAlgorithm(i))
+			}
 		}
 	}
 
+	g.updateGhostSpeeds()
 	physics.StepMove(&g.player.Entity, g.level)
+	if g.timeAttack != nil {
+		g.recordPath()
+	}
 	for _, ghost := range g.ghosts {
+		if frozen {
+			ghost.PrevPos = ghost.Pos
+			continue
+		}
 		physics.StepMove(&ghost.Entity, g.level)
 	}
+	g.renderer.UpdateAnimations(g.player)
+	g.updatePowerUps(1)
+	g.visitTile()
 
 	g.consumePellet()
-	g.checkAppleCollection()
-	g.updateSpeedBoost()
-
-	// Check win condition - only when all pellets are collected
-	if g.pelletsCollected >= g.level.TotalPellets {
-		g.finalScore = g.score
-		g.gameState = view.StateWon
-		return nil
+	if g.timeAttack != nil {
+		g.updateSplits()
 	}
-
-	g.checkCaught()
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-		g.initLevel()
+	if g.survival != nil {
+		g.updateSurvival()
 	}
+	g.updateItems(1)
+	g.checkItemCollection()
 
-	// Toggle debug mode
-	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
-		g.debugMode = !g.debugMode
+	if g.checkObjectives() {
+		return
 	}
 
-	return nil
+	g.checkCaught()
+	g.checkNearMiss()
+	g.updateAdaptive()
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
@@ -311,27 +500,113 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.renderer.DrawMenu(screen, g.menu, screenWidth, screenHeight)
 	} else if g.gameState == view.StatePlaying {
 		g.renderer.DrawLevel(screen, g.level)
-		g.renderer.DrawPlayer(screen, g.player)
-		g.renderer.DrawGhosts(screen, g.ghosts, g.debugMode, g.ghostAlgorithms)
-		g.renderer.DrawApples(screen, g.level.Apples)
+		alpha := 1.0
+		if g.turns == nil {
+			alpha = g.clock.Alpha()
+		}
+		if g.timeAttack != nil {
+			g.drawReplay(screen, alpha)
+		}
+		if g.dyingTicks > 0 {
+			g.renderer.DrawPlayerDeath(screen, g.player, g.deathProgress())
+		} else {
+			g.renderer.DrawPlayer(screen, g.player, alpha)
+			g.renderer.DrawGhosts(screen, g.ghosts, alpha, g.debugMode, g.ghostLabels())
+		}
+		g.renderer.DrawItems(screen, g.level.Items)
 		g.drawHUD(screen)
 	} else if g.gameState == view.StateWon {
-		g.renderer.DrawWinScreen(screen, g.finalScore, screenWidth, screenHeight)
+		details := []string{fmt.Sprintf("Level %d cleared", g.levelNumber)}
+		if g.turns != nil {
+			details = append(details, fmt.Sprintf("Moves: %d (par %d)", g.turns.moves, g.turns.par))
+		}
+		if earned, total := objective.Stars(g.objectives); total > 1 {
+			details = append(details, fmt.Sprintf("Stars: %d of %d", earned, total))
+			details = append(details, g.objectiveLines()...)
+		}
+		g.renderer.DrawWinScreen(screen, g.finalScore, details, g.instructions(true), screenWidth, screenHeight)
+	} else if g.gameState == view.StateGameOver {
+		var details []string
+		if g.survival != nil {
+			details = g.survivalDetails()
+		}
+		g.renderer.DrawGameOverScreen(screen, g.finalScore, details, g.instructions(false), screenWidth, screenHeight)
+	} else if g.gameState == view.StateResults {
+		g.drawResults(screen, screenWidth, screenHeight)
 	}
 }
 
+// instructions returns the end screen line naming the keys currently bound to
+// leave it, with the key for the next level when there is one
+func (g *Game) instructions(next bool) string {
+	keymap := g.controls.Keymap()
+	restart, back := keymap.Keys(input.ActionRestart), keymap.Keys(input.ActionBack)
+	if next {
+		return fmt.Sprintf("Press %s for the next level, %s to restart or %s for the menu", keymap.Keys(input.ActionNext), restart, back)
+	}
+	return fmt.Sprintf("Press %s to restart or %s to return to menu", restart, back)
+}
+
 func (g *Game) drawHUD(screen *ebiten.Image) {
 	screenWidth := screen.Bounds().Dx()
 
-	scoreMsg := fmt.Sprintf("Score: %d", g.score)
+	scoreMsg := fmt.Sprintf("Score: %d  Level: %d", g.scorer.Score(), g.levelNumber)
 	g.renderer.TextRenderer.DrawText(screen, scoreMsg, 10, 5, renderer.ColorMenuText, 8)
 
-	difficultyMsg := fmt.Sprintf("Difficulty: %s", g.difficulty.String())
+	// Turn-based play has undo instead of lives
+	if g.turns == nil {
+		g.renderer.DrawLives(screen, g.lives)
+	}
+	g.renderer.DrawItemHistory(screen, g.recentItems)
+
+	difficultyMsg := fmt.Sprintf("Difficulty: %s", g.diffConfig.Name)
 	g.renderer.TextRenderer.DrawText(screen, difficultyMsg, screenWidth-len(difficultyMsg)*9+5, 5, renderer.ColorMenuText, 8)
 
-	if g.speedBoostFrames > 0 {
-		boostMsg := fmt.Sprintf("SPEED BOOST! (%d)", g.speedBoostFrames/60+1)
-		g.renderer.TextRenderer.DrawText(screen, boostMsg, 10, 25, renderer.ColorSpeedBoost, 8)
+	if g.turns == nil && g.timeAttack == nil {
+		if speed := g.clock.Speed(); speed != 1 {
+			speedMsg := fmt.Sprintf("Speed: %.2gx", speed)
+			g.renderer.TextRenderer.DrawText(screen, speedMsg, screenWidth-len(speedMsg)*9+5, 25, renderer.ColorMenuText, 8)
+		}
+	}
+
+	// The left column stacks whichever status lines are showing
+	lineY := 25
+	if g.turns != nil {
+		movesMsg := fmt.Sprintf("Moves: %d  Par: %d", g.turns.moves, g.turns.par)
+		g.renderer.TextRenderer.DrawText(screen, movesMsg, 10, lineY, renderer.ColorMenuText, 8)
+		lineY += 20
+	}
+
+	if g.timeAttack != nil {
+		lineY = g.drawTimeAttackHUD(screen, lineY)
+	}
+	if g.survival != nil {
+		lineY = g.drawSurvivalHUD(screen, lineY)
+	}
+
+	if timers := g.powerUpTimers(); timers != "" {
+		g.renderer.TextRenderer.DrawText(screen, timers, 10, lineY, renderer.ColorSpeedBoost, 8)
+		lineY += 20
+	}
+
+	for _, line := range g.objectiveLines() {
+		g.renderer.TextRenderer.DrawText(screen, line, 10, lineY, renderer.ColorMenuText, 8)
+		lineY += 14
+	}
+	if len(g.objectives) > 0 {
+		lineY += 6
+	}
+
+	if g.debugMode && g.adaptive != nil {
+		adj := g.adaptive.Current()
+		adaptiveMsg := fmt.Sprintf("Adaptive: %+.2f x%.2f T%+d", adj.Intensity, adj.SpeedScale, adj.TierShift)
+		g.renderer.TextRenderer.DrawText(screen, adaptiveMsg, 10, lineY, renderer.ColorSpeedBoost, 8)
+		lineY += 20
+	}
+
+	if g.turns != nil && g.turns.caught {
+		caughtMsg := "CAUGHT! Undo to step back or restart"
+		g.renderer.TextRenderer.DrawText(screen, caughtMsg, 10, lineY, renderer.ColorSpeedBoost, 8)
 	}
 }
 
@@ -342,7 +617,7 @@ func (g *Game) setDifficulty(difficulty config.Difficulty) {
 
 // Layout returns the game's logical screen size
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	if g.gameState == view.StateMenu || g.gameState == view.StateWon {
+	if g.gameState == view.StateMenu || g.gameState == view.StateWon || g.gameState == view.StateGameOver || g.gameState == view.StateResults {
 		return outsideWidth, outsideHeight
 	}
 	if g.level != nil {
@@ -351,104 +626,148 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return outsideWidth, outsideHeight
 }
 
-// spawnApples randomly spawns 2-3 apples on the level
-func (g *Game) spawnApples() {
-	g.level.Apples = make([]*model.Apple, 0)
-
-	walkableTiles := g.level.GetWalkableTiles()
-	if len(walkableTiles) == 0 {
-		return
-	}
+// initLevel starts a new game on the first level
+func (g *Game) initLevel() {
+	g.levelNumber = 1
+	g.scorer = scoring.New(g.rules.Scoring)
+	g.lives = g.rules.Lives
+	g.recentItems = nil
+	g.loadLevel()
+}
 
-	// Spawn 2-3 apples
-	numApples := 2 + rand.Intn(2) // 2 or 3 apples
-	appleColor := renderer.ColorApple
-
-	usedTiles := make(map[types.Tile]bool)
-
-	for i := 0; i < numApples && i < len(walkableTiles); i++ {
-		// Find a random unused tile
-		var tile types.Tile
-		attempts := 0
-		for {
-			tile = walkableTiles[rand.Intn(len(walkableTiles))]
-			if !usedTiles[tile] {
-				// Make sure it's not too close to player spawn
-				playerSpawn, _ := g.level.GetDefaultSpawnPoints()
-				if tile.X != playerSpawn.X || tile.Y != playerSpawn.Y {
-					break
-				}
-			}
-			attempts++
-			if attempts > 100 { // Prevent infinite loop
-				break
-			}
-		}
+// nextLevel moves on to the next level, keeping the score, lives and item history
+func (g *Game) nextLevel() {
+	g.levelNumber++
+	g.loadLevel()
+	g.gameState = view.StatePlaying
+}
 
-		usedTiles[tile] = true
-		g.level.AddApple(tile.X, tile.Y, appleColor)
-		// Set the position of the last added apple
-		if len(g.level.Apples) > 0 {
-			lastApple := g.level.Apples[len(g.level.Apples)-1]
-			lastApple.Pos = physics.TileCenter(tile.X, tile.Y)
-		}
+// loadLevel builds the maze and entities of the current level from its rules
+func (g *Game) loadLevel() {
+	// Time attack keeps a fixed seed so runs can be compared and replayed
+	g.seed = g.rules.Seed
+	if g.seed == 0 && g.mode != config.ModeTimeAttack {
+		g.seed = time.Now().UnixNano()
 	}
-}
+	g.rngState = rand.NewPCG(uint64(g.seed), 0)
+	g.rng = rand.New(g.rngState)
 
-// initLevel initializes the game level and entities
-func (g *Game) initLevel() {
-	g.level = model.New(nil) // Use default level data
-	g.score = 0
+	g.levelRules = g.rules.LevelFor(g.levelNumber)
+	g.level = model.New(g.levelRules.Maze)
 	g.pelletsCollected = 0
-	g.frame = 0
-	g.speedBoostFrames = 0
-	g.basePlayerSpeed = PlayerSpeed
-
-	diffConfig := config.GetDifficultyConfig(g.difficulty)
+	g.tick = 0
+	g.levelTicks = 0
+	g.ghostsEaten = 0
+	g.fruitCollected = 0
+	g.fruitMissed = 0
+	g.reachedExit = false
+	g.turnBuffer.Clear()
+	g.controls.Reset()
+	g.clock.Reset()
+	g.powerups = powerup.New(g.rules.PowerUps, g.clock)
+	g.dyingTicks = 0
+	g.basePlayerSpeed = g.rules.PlayerSpeed
+
+	diffConfig := g.rules.Preset(g.difficulty)
+	g.diffConfig = diffConfig
 	g.recalcEvery = diffConfig.RecalcEvery
 
 	g.distMap = intelligence.NewDistanceMap(g.level.Width, g.level.Height)
+	if g.rules.GhostAvoidance {
+		g.level.Reservations = model.NewReservations(g.rules.AvoidanceCost)
+	}
 
 	playerSpawn, ghostSpawns := g.level.GetDefaultSpawnPoints()
 
-	g.player = model.NewPlayer(playerSpawn.X, playerSpawn.Y, PlayerSpeed, renderer.ColorPac)
+	g.player = model.NewPlayer(playerSpawn.X, playerSpawn.Y, g.speedPerTick(g.rules.PlayerSpeed), renderer.ColorPac)
 	g.player.Pos = physics.TileCenter(playerSpawn.X, playerSpawn.Y)
+	g.player.PrevPos = g.player.Pos
+	g.player.Cornering = types.FixedFromFloat(g.rules.PlayerCornering)
+	g.player.Collider = model.NewCollider(g.rules.Colliders.Player)
+	if g.momentumEnabled && g.mode != config.ModeTurnBased {
+		g.player.Momentum = g.newMomentum()
+	}
 
 	g.ghosts = nil
-	for i, spawn := range ghostSpawns {
-		if i >= len(diffConfig.GhostSpeeds) {
-			break
-		}
+	g.ghostBaseSpeeds = nil
+	ghostCount := min(len(ghostSpawns), len(diffConfig.GhostSpeeds))
+	if g.mode == config.ModeSurvival {
+		ghostCount = g.rules.Survival.StartGhosts
+	}
+	for i := range ghostCount {
+		g.addGhost(ghostSpawns[i%len(ghostSpawns)])
+	}
 
-		ghostColor := renderer.ColorGhosts[i%len(renderer.ColorGhosts)]
-		ghostSpeed := diffConfig.GhostSpeeds[i]
-		skillLevel := config.GhostSkillLevelNormal
+	g.adaptive = nil
+	g.nearMissCooldown = 0
+	if g.adaptiveEnabled {
+		g.adaptive = adaptive.New(g.rules.Adaptive, g.clock.TickRate())
+	}
 
-		ghost := model.NewGhost(spawn.X, spawn.Y, ghostSpeed, ghostColor, skillLevel)
-		ghost.Pos = physics.TileCenter(spawn.X, spawn.Y)
-		g.ghosts = append(g.ghosts, ghost)
+	g.survival = nil
+	if g.mode == config.ModeSurvival {
+		g.initSurvival()
 	}
 
-	// Spawn apples
-	g.spawnApples()
+	g.itemsSpawned = make(map[string]int)
+	g.respawns = nil
+	g.spawner = spawn.NewSpawner(g.level.Width, g.level.Height)
 
 	// Assign ghost algorithms based on difficulty
 	g.assignGhostAlgorithms()
 
 	g.distMap.BuildBFS(g.player.Pos, g.level)
+
+	g.turns = nil
+	if g.mode == config.ModeTurnBased {
+		g.initTurns()
+	}
+	g.timeAttack = nil
+	if g.mode == config.ModeTimeAttack {
+		// Runs are timed at normal speed so they stay comparable
+		g.clock.SetSpeed(1)
+		g.initTimeAttack()
+	} else {
+		g.clock.SetSpeed(g.rules.GameSpeed)
+	}
+	g.objectives = nil
+	if g.survival == nil {
+		g.objectives = objective.Evaluate(g.levelRules.Objectives, g.progress())
+	}
+}
+
+// addGhost puts another ghost on the board at the given spawn tile. Ghosts
+// take their color, speed and skill from their index, cycling through the
+// difficulty preset when there are more ghosts than it lists.
+func (g *Game) addGhost(spawn types.Tile) {
+	i := len(g.ghosts)
+	ghostColor := renderer.ColorGhosts[i%len(renderer.ColorGhosts)]
+	ghostSpeed := g.diffConfig.GhostSpeeds[i%len(g.diffConfig.GhostSpeeds)]
+	skillLevel := config.GhostSkillLevelNormal
+	if len(g.diffConfig.SkillLevels) > 0 {
+		skillLevel = g.diffConfig.SkillLevels[i%len(g.diffConfig.SkillLevels)]
+	}
+
+	ghost := model.NewGhost(spawn.X, spawn.Y, g.speedPerTick(ghostSpeed), ghostColor, skillLevel)
+	ghost.Pos = physics.TileCenter(spawn.X, spawn.Y)
+	ghost.PrevPos = ghost.Pos
+	ghost.Cornering = types.FixedFromFloat(g.rules.GhostCornering)
+	ghost.Collider = model.NewCollider(g.rules.Colliders.Ghost)
+	g.ghosts = append(g.ghosts, ghost)
+	g.ghostBaseSpeeds = append(g.ghostBaseSpeeds, ghostSpeed)
 }
 
 func (g *Game) Run() error {
 	g.difficulty = config.DifficultyEasy
 
-	// Initialize random seed
-	rand.Seed(time.Now().UnixNano())
-
 	ebiten.SetWindowTitle("Pacman")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
 	ebiten.SetWindowSize(800, 600)
 
+	// Simulation ticks are driven by the clock, so update once per rendered frame
+	ebiten.SetTPS(ebiten.SyncWithFPS)
+
 	err := ebiten.RunGame(g)
 
 	if g.shouldExit {
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"log"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/spawn"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+	"github.com/vladyslavpavlenko/pacman/internal/view/renderer"
+)
+
+// maxRecentItems is how many collected items the HUD remembers
+const maxRecentItems = 7
+
+// itemRespawn is a copy of an item waiting to appear again
+type itemRespawn struct {
+	id    string
+	ticks int
+}
+
+// updateItems advances the item spawn rules by the given number of ticks:
+// items appear once enough pellets are eaten or their respawn time is up,
+// and vanish when their own time is up
+func (g *Game) updateItems(ticks int) {
+	for _, id := range g.rules.ItemIDs() {
+		def := g.rules.Items[id]
+		if !def.OnLevel(g.levelNumber) {
+			continue
+		}
+
+		schedule := def.Spawn.AtPellets
+		for g.itemsSpawned[id] < len(schedule) && g.pelletsCollected >= schedule[g.itemsSpawned[id]] {
+			g.spawnItem(id, def)
+			g.itemsSpawned[id]++
+		}
+	}
+
+	pending := g.respawns[:0]
+	for _, r := range g.respawns {
+		r.ticks -= ticks
+		if r.ticks > 0 {
+			pending = append(pending, r)
+			continue
+		}
+		if def, ok := g.rules.Items[r.id]; ok && def.OnLevel(g.levelNumber) {
+			g.spawnItem(r.id, def)
+		}
+	}
+	g.respawns = pending
+
+	for i := len(g.level.Items) - 1; i >= 0; i-- {
+		item := g.level.Items[i]
+		if item.TicksLeft == 0 {
+			continue
+		}
+		item.TicksLeft = max(item.TicksLeft-ticks, 0)
+		if item.TicksLeft == 0 {
+			// A history item that vanishes for good can no longer be collected
+			if def := g.rules.Items[item.ID]; def.History && def.Spawn.Respawn == 0 {
+				g.fruitMissed++
+			}
+			g.removeItem(item)
+		}
+	}
+}
+
+// spawnItem places an item where the placement strategy for the current
+// level and mode puts it
+func (g *Game) spawnItem(id string, def config.ItemDef) {
+	strategy := g.rules.SpawnStrategyFor(def, g.levelNumber, g.mode)
+	tile, err := g.spawner.Place(strategy, g.spawnContext())
+	if err != nil {
+		log.Printf("spawn item %s: %v", id, err)
+		return
+	}
+
+	item := model.NewItem(id, def, tile, physics.TileCenter(tile.X, tile.Y), renderer.ColorItem)
+	if def.Spawn.Time > 0 {
+		item.TicksLeft = max(g.clock.Ticks(def.Spawn.Time), 1)
+	}
+	g.level.AddItem(item)
+}
+
+// spawnContext describes the maze for the placement strategies. Items never
+// appear on anyone's spawn tile.
+func (g *Game) spawnContext() spawn.Context {
+	playerX, playerY := physics.PosToTile(g.player.Pos)
+	ctx := spawn.Context{
+		Level:       g.level,
+		Player:      types.Tile{X: playerX, Y: playerY},
+		Blocked:     map[types.Tile]bool{g.player.SpawnTile: true},
+		MinDistance: g.rules.Spawn.MinDistance,
+		Rand:        g.rng,
+	}
+	for _, ghost := range g.ghosts {
+		ghostX, ghostY := physics.PosToTile(ghost.Pos)
+		ctx.Ghosts = append(ctx.Ghosts, types.Tile{X: ghostX, Y: ghostY})
+		ctx.Blocked[ghost.SpawnTile] = true
+	}
+	return ctx
+}
+
+// removeItem takes an item out of the maze, collected or not, and schedules
+// its next copy when the item respawns
+func (g *Game) removeItem(item *model.Item) {
+	g.level.RemoveItem(item)
+	if def := g.rules.Items[item.ID]; def.Spawn.Respawn > 0 {
+		g.respawns = append(g.respawns, itemRespawn{id: item.ID, ticks: max(g.clock.Ticks(def.Spawn.Respawn), 1)})
+	}
+}
+
+// checkItemCollection collects any item the player touches, scoring it and
+// granting its effects
+func (g *Game) checkItemCollection() {
+	for i := len(g.level.Items) - 1; i >= 0; i-- {
+		item := g.level.Items[i]
+		if !physics.CheckContact(&g.player.Entity, &item.Entity) {
+			continue
+		}
+
+		def := g.rules.Items[item.ID]
+		g.removeItem(item)
+		g.award(g.scorer.Item(def.Score))
+
+		if def.History {
+			g.fruitCollected++
+			g.recentItems = append(g.recentItems, item.Sprite)
+			if len(g.recentItems) > maxRecentItems {
+				g.recentItems = g.recentItems[1:]
+			}
+		}
+
+		g.grantPowerUps(def.Effects)
+	}
+}
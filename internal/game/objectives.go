@@ -0,0 +1,81 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/vladyslavpavlenko/pacman/internal/logic/objective"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/view"
+)
+
+// progress gathers what the player has achieved in the level so far
+func (g *Game) progress() objective.Progress {
+	totalFruit := 0
+	for _, def := range g.rules.Items {
+		if def.History && def.OnLevel(g.levelNumber) {
+			totalFruit += len(def.Spawn.AtPellets)
+		}
+	}
+
+	return objective.Progress{
+		Pellets:      g.pelletsCollected,
+		TotalPellets: g.level.TotalPellets,
+		Ghosts:       g.ghostsEaten,
+		Fruit:        g.fruitCollected,
+		FruitMissed:  g.fruitMissed,
+		TotalFruit:   totalFruit,
+		Seconds:      g.clock.Seconds(g.levelTicks),
+		ReachedExit:  g.reachedExit,
+	}
+}
+
+// checkObjectives re-evaluates the level's objectives and ends the level once
+// it is won or can no longer be. It reports whether the level ended.
+func (g *Game) checkObjectives() bool {
+	// Survival has no objectives and only ends when the lives run out
+	if g.survival != nil {
+		return false
+	}
+
+	tileX, tileY := physics.PosToTile(g.player.Pos)
+	if g.level.IsExit(tileX, tileY) {
+		g.reachedExit = true
+	}
+
+	g.objectives = objective.Evaluate(g.levelRules.Objectives, g.progress())
+	switch {
+	case objective.Won(g.levelRules, g.objectives):
+		if g.timeAttack != nil {
+			g.finishTimeAttack()
+			return true
+		}
+		g.finalScore = g.scorer.Score()
+		g.gameState = view.StateWon
+		return true
+	case objective.Lost(g.levelRules, g.objectives):
+		g.finalScore = g.scorer.Score()
+		g.gameState = view.StateGameOver
+		return true
+	}
+	return false
+}
+
+// objectiveLines describes each objective with a mark for whether it is met
+// or failed; optional objectives are flagged as earning a star
+func (g *Game) objectiveLines() []string {
+	lines := make([]string, len(g.objectives))
+	for i, s := range g.objectives {
+		mark := "[ ]"
+		switch {
+		case s.Met:
+			mark = "[x]"
+		case s.Failed:
+			mark = "[-]"
+		}
+		lines[i] = fmt.Sprintf("%s %s", mark, s.Text)
+		if s.Objective.Optional {
+			lines[i] += " (star)"
+		}
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/powerup"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+)
+
+// grantPowerUps starts each of the named effects. A shield is what lets the
+// player eat ghosts, so it scores like a power pellet and starts a new chain.
+func (g *Game) grantPowerUps(effects []string) {
+	for _, effect := range effects {
+		if err := g.powerups.Grant(effect); err != nil {
+			log.Printf("grant power-up: %v", err)
+			continue
+		}
+		if effect == powerup.Shield {
+			g.award(g.scorer.PowerPellet())
+		}
+	}
+	g.applyPowerUps()
+}
+
+// updatePowerUps runs the power-up timers down by the given number of ticks
+func (g *Game) updatePowerUps(ticks int) {
+	g.powerups.Update(ticks)
+	g.applyPowerUps()
+}
+
+// applyPowerUps sets the player's speed and phasing from the active effects
+// and ends the ghost chain once the shield is gone. The other effects are
+// checked where they apply.
+func (g *Game) applyPowerUps() {
+	if !g.powerups.Has(powerup.Shield) {
+		g.scorer.EndChain()
+	}
+
+	speed := g.basePlayerSpeed
+	if boost := g.powerups.Strength(powerup.Speed); boost > 0 {
+		speed *= boost
+	}
+	g.player.Speed = g.speedPerTick(speed)
+
+	// A player still inside a wall keeps phasing until it comes out
+	tileX, tileY := physics.PosToTile(g.player.Pos)
+	g.player.Phasing = g.powerups.Has(powerup.Phasing) || !g.level.CanWalk(tileX, tileY)
+}
+
+// shieldBlocks stops a catch by the given ghost while the player's shield is
+// up. The ghost counts as eaten and is sent back to its spawn, and the shield
+// stays up so every ghost until it runs out scores the next chain value.
+func (g *Game) shieldBlocks(ghost *model.Ghost) bool {
+	if !g.powerups.Has(powerup.Shield) {
+		return false
+	}
+	g.award(g.scorer.Ghost())
+	g.ghostsEaten++
+	physics.ResetGhost(ghost)
+	return true
+}
+
+// powerUpTimers returns the HUD line listing every active effect with the
+// seconds it has left
+func (g *Game) powerUpTimers() string {
+	var timers []string
+	for _, a := range g.powerups.Active() {
+		seconds := int(math.Ceil(g.clock.Seconds(a.TicksLeft)))
+		timers = append(timers, fmt.Sprintf("%s %d", a.Type.Label, seconds))
+	}
+	return strings.Join(timers, "  ")
+}
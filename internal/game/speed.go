@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/vladyslavpavlenko/pacman/internal/logic/powerup"
+)
+
+// leadGhost is the index of the ghost that turns into Cruise Elroy
+const leadGhost = 0
+
+// elroyStage returns the Cruise Elroy stage of the lead ghost: 0 when inactive,
+// 1 or 2 once the remaining pellets drop below the level's thresholds. Survival
+// escalates through its waves instead.
+func (g *Game) elroyStage() int {
+	if g.survival != nil {
+		return 0
+	}
+	rule := g.rules.ElroyFor(g.levelNumber)
+	remaining := g.level.TotalPellets - g.pelletsCollected
+
+	switch {
+	case remaining <= rule.Stage2Pellets:
+		return 2
+	case remaining <= rule.Stage1Pellets:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ghostSpeed returns the current speed of the ghost at the given index in tiles per second
+func (g *Game) ghostSpeed(i int) float64 {
+	speed := g.ghostBaseSpeeds[i]
+
+	if g.adaptive != nil {
+		speed *= g.adaptive.Current().SpeedScale
+	}
+	if g.survival != nil {
+		speed *= g.rules.Survival.SpeedScale(g.clock.Seconds(g.levelTicks))
+	}
+
+	if i == leadGhost {
+		rule := g.rules.ElroyFor(g.levelNumber)
+		switch g.elroyStage() {
+		case 1:
+			speed *= rule.Stage1Speed
+		case 2:
+			speed *= rule.Stage2Speed
+		}
+	}
+
+	return speed
+}
+
+// updateGhostSpeeds recomputes every ghost's speed from the current game state
+func (g *Game) updateGhostSpeeds() {
+	for i, ghost := range g.ghosts {
+		if i < len(g.ghostBaseSpeeds) {
+			ghost.Speed = g.speedPerTick(g.ghostSpeed(i))
+		}
+	}
+}
+
+// ghostAlgorithm returns the algorithm the ghost at the given index follows
+// right now. Cruise Elroy keeps chasing whatever its assigned algorithm is,
+// and every ghost wanders while the player is invisible.
+func (g *Game) ghostAlgorithm(i int) string {
+	if g.powerups.Has(powerup.Invisibility) {
+		return "Random"
+	}
+	if i == leadGhost && g.elroyStage() > 0 {
+		return "Chase"
+	}
+	return g.ghostAlgorithms[i]
+}
+
+// ghostLabels returns the debug labels drawn above the ghosts
+func (g *Game) ghostLabels() []string {
+	labels := make([]string, len(g.ghostAlgorithms))
+	for i := range g.ghostAlgorithms {
+		labels[i] = g.ghostAlgorithm(i)
+		if stage := g.elroyStage(); i == leadGhost && stage > 0 && labels[i] == "Chase" {
+			labels[i] = fmt.Sprintf("Elroy%d", stage)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+	"github.com/vladyslavpavlenko/pacman/internal/view/renderer"
+)
+
+// survival tracks the waves of an endless survival game
+type survival struct {
+	wave            int
+	waveTicks       int   // ticks until the next wave
+	collectedAtWave int   // pellets collected when the current wave started
+	ranks           []int // each ghost's place on the algorithm ladder
+}
+
+// initSurvival starts the first wave with the starting ghosts at the bottom
+// of the ladder
+func (g *Game) initSurvival() {
+	g.survival = &survival{
+		wave:      1,
+		waveTicks: g.clock.Ticks(g.rules.Survival.WaveTime),
+		ranks:     make([]int, len(g.ghosts)),
+	}
+}
+
+// updateSurvival scores the time survived and starts the next wave once the
+// wave time is up or every pellet has been eaten
+func (g *Game) updateSurvival() {
+	s := g.survival
+	if g.levelTicks%g.clock.TickRate() == 0 {
+		g.award(g.scorer.Second())
+	}
+
+	s.waveTicks--
+	cleared := g.pelletsCollected-s.collectedAtWave >= g.level.TotalPellets
+	if s.waveTicks <= 0 || cleared {
+		g.nextWave()
+	}
+}
+
+// nextWave refills the pellets and escalates the ghosts: waves alternate
+// between adding a ghost and upgrading one, falling back to the other once
+// the ghosts are at the cap or the top of the ladder
+func (g *Game) nextWave() {
+	s := g.survival
+	s.wave++
+	s.waveTicks = g.clock.Ticks(g.rules.Survival.WaveTime)
+	g.level.RefillPellets()
+	s.collectedAtWave = g.pelletsCollected
+
+	canAdd := len(g.ghosts) < g.rules.Survival.MaxGhosts
+	if s.wave%2 == 0 || !canAdd {
+		if g.upgradeGhost() {
+			return
+		}
+	}
+	if canAdd {
+		g.addSurvivalGhost()
+	}
+}
+
+// addSurvivalGhost brings in a new ghost at the bottom of the ladder, at the
+// spawn point farthest from the player
+func (g *Game) addSurvivalGhost() {
+	_, spawns := g.level.GetDefaultSpawnPoints()
+	tileX, tileY := physics.PosToTile(g.player.Pos)
+	distance := func(t types.Tile) int {
+		return abs(t.X-tileX) + abs(t.Y-tileY)
+	}
+
+	farthest := spawns[0]
+	for _, spawn := range spawns[1:] {
+		if distance(spawn) > distance(farthest) {
+			farthest = spawn
+		}
+	}
+
+	g.addGhost(farthest)
+	g.survival.ranks = append(g.survival.ranks, 0)
+	g.assignGhostAlgorithms()
+}
+
+// upgradeGhost moves the lowest-ranked ghost one step up the ladder. It
+// reports false when every ghost is already at the top.
+func (g *Game) upgradeGhost() bool {
+	s := g.survival
+	lowest := -1
+	for i, rank := range s.ranks {
+		if rank < len(g.rules.Survival.Ladder)-1 && (lowest < 0 || rank < s.ranks[lowest]) {
+			lowest = i
+		}
+	}
+	if lowest < 0 {
+		return false
+	}
+
+	s.ranks[lowest]++
+	g.assignGhostAlgorithms()
+	return true
+}
+
+// survivalAlgorithm returns the ladder algorithm of the ghost at the given index
+func (g *Game) survivalAlgorithm(i int) string {
+	ladder := g.rules.Survival.Ladder
+	return ladder[min(g.survival.ranks[i], len(ladder)-1)]
+}
+
+// drawSurvivalHUD draws the wave and time survived, starting at lineY in the
+// HUD's left column, and returns where the next line goes
+func (g *Game) drawSurvivalHUD(screen *ebiten.Image, lineY int) int {
+	s := g.survival
+	next := int(math.Ceil(g.clock.Seconds(s.waveTicks)))
+	waveMsg := fmt.Sprintf("Wave %d  Next in %ds  Time %s", s.wave, next, formatTime(g.clock.Seconds(g.levelTicks)))
+	g.renderer.TextRenderer.DrawText(screen, waveMsg, 10, lineY, renderer.ColorMenuText, 8)
+	return lineY + 20
+}
+
+// survivalDetails returns the game over lines summing up a survival game
+func (g *Game) survivalDetails() []string {
+	return []string{
+		fmt.Sprintf("Survived: %s", formatTime(g.clock.Seconds(g.levelTicks))),
+		fmt.Sprintf("Wave: %d", g.survival.wave),
+		fmt.Sprintf("Pellets: %d", g.pelletsCollected),
+	}
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
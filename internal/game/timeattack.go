@@ -0,0 +1,186 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/vladyslavpavlenko/pacman/internal/records"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+	"github.com/vladyslavpavlenko/pacman/internal/view"
+	"github.com/vladyslavpavlenko/pacman/internal/view/renderer"
+)
+
+// splitCount is how many splits a run takes, one per pellet quartile
+const splitCount = 4
+
+// timeAttack tracks a time-attack run against the personal best
+type timeAttack struct {
+	key     string
+	best    records.Run
+	hasBest bool
+	splits  []int            // ticks at which each pellet quartile was reached
+	path    [][2]types.Fixed // player position after each tick
+	newBest bool
+}
+
+// initTimeAttack starts a time-attack run, looking up the best to race
+func (g *Game) initTimeAttack() {
+	key := records.Setup{
+		Level:      g.levelNumber,
+		Difficulty: g.diffConfig.Name,
+		Momentum:   g.player.Momentum != nil,
+		Adaptive:   g.adaptive != nil,
+		Seed:       g.seed,
+	}.Key()
+	best, ok := g.records.Best(key)
+	g.timeAttack = &timeAttack{key: key, best: best, hasBest: ok}
+	g.recordPath()
+}
+
+// recordPath adds the player's position to the run's path
+func (g *Game) recordPath() {
+	ta := g.timeAttack
+	ta.path = append(ta.path, [2]types.Fixed{g.player.Pos.X, g.player.Pos.Y})
+}
+
+// drawReplay draws the best run as it was at the same time into the level. It
+// has no collision and only shows the pace to beat. The best run may have
+// been recorded at another tick rate, so the time is converted to its ticks.
+func (g *Game) drawReplay(screen *ebiten.Image, alpha float64) {
+	ta := g.timeAttack
+	path := ta.best.Path
+	if !g.replayEnabled || !ta.hasBest || ta.best.TickRate <= 0 {
+		return
+	}
+
+	recorded := func(ticks int) int {
+		return ticks * ta.best.TickRate / g.clock.TickRate()
+	}
+	i := recorded(g.levelTicks)
+	if i >= len(path) {
+		return
+	}
+
+	prev, cur := path[recorded(max(g.levelTicks-1, 0))], path[i]
+	g.renderer.DrawReplay(screen, types.Point{X: prev[0], Y: prev[1]}, types.Point{X: cur[0], Y: cur[1]}, alpha)
+}
+
+// updateSplits records a split whenever the player eats into another quarter
+// of the pellets
+func (g *Game) updateSplits() {
+	ta := g.timeAttack
+	for len(ta.splits) < splitCount {
+		quartile := len(ta.splits) + 1
+		// Round up so the last split always needs every pellet
+		target := (g.level.TotalPellets*quartile + splitCount - 1) / splitCount
+		if g.pelletsCollected < target {
+			break
+		}
+		ta.splits = append(ta.splits, g.levelTicks)
+	}
+}
+
+// finishTimeAttack records the cleared run and shows the results
+func (g *Game) finishTimeAttack() {
+	ta := g.timeAttack
+	run := records.Run{
+		Ticks:    g.levelTicks,
+		TickRate: g.clock.TickRate(),
+		Splits:   ta.splits,
+		Seed:     g.seed,
+		Path:     ta.path,
+	}
+	ta.newBest = g.records.Submit(ta.key, run)
+	if ta.newBest {
+		if err := g.records.Save(); err != nil {
+			log.Printf("save records: %v", err)
+		}
+	}
+
+	g.finalScore = g.scorer.Score()
+	g.gameState = view.StateResults
+}
+
+// splitDelta returns how far the i-th split is ahead of (negative) or behind
+// (positive) the same split of the best run
+func (g *Game) splitDelta(i int) (float64, bool) {
+	ta := g.timeAttack
+	if !ta.hasBest || i >= len(ta.splits) {
+		return 0, false
+	}
+	best, ok := ta.best.SplitSeconds(i)
+	if !ok {
+		return 0, false
+	}
+	return g.clock.Seconds(ta.splits[i]) - best, true
+}
+
+// drawTimeAttackHUD draws the running timer and the latest split, starting at
+// lineY in the HUD's left column, and returns where the next line goes
+func (g *Game) drawTimeAttackHUD(screen *ebiten.Image, lineY int) int {
+	timeMsg := fmt.Sprintf("Time %s", formatTime(g.clock.Seconds(g.levelTicks)))
+	g.renderer.TextRenderer.DrawText(screen, timeMsg, 10, lineY, renderer.ColorMenuText, 8)
+	lineY += 20
+
+	ta := g.timeAttack
+	if n := len(ta.splits); n > 0 {
+		splitMsg := fmt.Sprintf("Split %d%%: %s", n*100/splitCount, formatTime(g.clock.Seconds(ta.splits[n-1])))
+		splitColor := renderer.ColorMenuText
+		if delta, ok := g.splitDelta(n - 1); ok {
+			splitMsg += fmt.Sprintf(" (%+.2f)", delta)
+			splitColor = deltaColor(delta)
+		}
+		g.renderer.TextRenderer.DrawText(screen, splitMsg, 10, lineY, splitColor, 8)
+		lineY += 20
+	}
+	return lineY
+}
+
+// drawResults draws the time-attack results screen
+func (g *Game) drawResults(screen *ebiten.Image, screenWidth, screenHeight int) {
+	ta := g.timeAttack
+
+	var rows []renderer.ResultRow
+	for i, split := range ta.splits {
+		row := renderer.ResultRow{
+			Label: fmt.Sprintf("%d%%", (i+1)*100/splitCount),
+			Time:  formatTime(g.clock.Seconds(split)),
+			Color: renderer.ColorMenuText,
+		}
+		if delta, ok := g.splitDelta(i); ok {
+			row.Delta = fmt.Sprintf("%+.2f", delta)
+			row.Color = deltaColor(delta)
+		}
+		rows = append(rows, row)
+	}
+
+	total := renderer.ResultRow{Label: "Total", Time: formatTime(g.clock.Seconds(g.levelTicks)), Color: renderer.ColorMenuText}
+	if ta.hasBest {
+		delta := g.clock.Seconds(g.levelTicks) - ta.best.Seconds()
+		total.Delta = fmt.Sprintf("%+.2f", delta)
+		total.Color = deltaColor(delta)
+	}
+	rows = append(rows, total)
+
+	title := "LEVEL CLEAR"
+	if ta.newBest {
+		title = "NEW BEST!"
+	}
+	g.renderer.DrawResultsScreen(screen, title, g.finalScore, rows, g.instructions(true), screenWidth, screenHeight)
+}
+
+// deltaColor colors a time delta: green when ahead of the best, red when behind
+func deltaColor(delta float64) color.RGBA {
+	if delta <= 0 {
+		return renderer.ColorAhead
+	}
+	return renderer.ColorBehind
+}
+
+// formatTime formats seconds as minutes, seconds and hundredths
+func formatTime(seconds float64) string {
+	minutes := int(seconds) / 60
+	return fmt.Sprintf("%d:%05.2f", minutes, seconds-float64(minutes*60))
+}
@@ -0,0 +1,219 @@
+package game
+
+import (
+	"maps"
+	"math/rand/v2"
+
+	"github.com/vladyslavpavlenko/pacman/internal/input"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/intelligence"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/objective"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/powerup"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/scoring"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/spawn"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// turnState tracks a turn-based run, where the ghosts act only after the
+// player has moved one tile
+type turnState struct {
+	moves   int
+	par     int
+	caught  bool
+	energy  []float64 // tiles each ghost has banked toward its next step
+	history []turnSnapshot
+}
+
+// turnSnapshot is everything a turn changes, kept so the turn can be undone
+type turnSnapshot struct {
+	player           model.Player
+	ghosts           []model.Ghost
+	energy           []float64
+	grid             [][]model.Tile
+	items            []model.Item
+	itemsSpawned     map[string]int
+	recentItems      []string
+	respawns         []itemRespawn
+	spawner          *spawn.Spawner
+	reservations     *model.Reservations
+	rngState         rand.PCG
+	powerups         []powerup.Active
+	scorer           scoring.Scorer
+	pelletsCollected int
+	levelTicks       int
+	ghostsEaten      int
+	fruitCollected   int
+	fruitMissed      int
+	reachedExit      bool
+	moves            int
+	caught           bool
+}
+
+// initTurns starts a turn-based run on the freshly initialised level
+func (g *Game) initTurns() {
+	g.consumePellet()
+
+	spawnX, spawnY := physics.PosToTile(g.player.Pos)
+	g.turns = &turnState{
+		par:    intelligence.PelletTour(g.level, types.Tile{X: spawnX, Y: spawnY}),
+		energy: make([]float64, len(g.ghosts)),
+	}
+}
+
+// updateTurns handles one frame of turn-based play: a direction key takes a
+// turn and the undo key takes the last one back
+func (g *Game) updateTurns() {
+	if g.controls.Keymap().JustPressed(input.ActionUndo) {
+		g.undoTurn()
+		return
+	}
+
+	if dir, ok := g.controls.JustPressedDirection(); ok {
+		g.takeTurn(dir)
+	}
+}
+
+// takeTurn moves the player one tile and lets the ghosts answer. Walking into
+// a wall does not use up a turn.
+func (g *Game) takeTurn(dir types.Vector) {
+	if g.turns.caught || !physics.CanTurn(&g.player.Entity, dir, g.level) {
+		return
+	}
+
+	g.turns.history = append(g.turns.history, g.snapshotTurn())
+
+	physics.StepTile(&g.player.Entity, dir, g.level)
+	g.turns.moves++
+	g.renderer.UpdateAnimations(g.player)
+	g.visitTile()
+	// A turn lasts as long as the player takes to cross a tile in real time
+	turnTicks := g.clock.Ticks(1 / g.basePlayerSpeed)
+	g.levelTicks += turnTicks
+	g.updatePowerUps(turnTicks)
+	g.consumePellet()
+	g.updateItems(turnTicks)
+	g.checkItemCollection()
+
+	if g.checkObjectives() {
+		return
+	}
+
+	if g.turnCaught() {
+		g.turns.caught = true
+		return
+	}
+
+	// Frozen ghosts skip their turns without banking them
+	if g.powerups.Has(powerup.Freeze) {
+		return
+	}
+
+	g.distMap.BuildBFS(g.player.Pos, g.level)
+	intelligence.ReserveTiles(g.ghosts, g.level)
+
+	// Ghosts keep their speed relative to the player, so a ghost at 60% of
+	// the player's speed steps on three turns out of five
+	for i, ghost := range g.ghosts {
+		if i >= len(g.ghostAlgorithms) {
+			continue
+		}
+
+		g.turns.energy[i] += g.ghostSpeed(i) / g.basePlayerSpeed
+		for g.turns.energy[i] >= 1 {
+			g.turns.energy[i]--
+			g.updateGhostAI(ghost, g.ghostAlgorithm(i))
+			physics.StepTile(&ghost.Entity, ghost.WantDir, g.level)
+
+			if g.turnCaught() {
+				g.turns.caught = true
+				return
+			}
+		}
+	}
+}
+
+// turnCaught reports whether a ghost shares the player's tile. While the
+// shield is up such a ghost is sent home instead.
+func (g *Game) turnCaught() bool {
+	playerX, playerY := physics.PosToTile(g.player.Pos)
+	for _, ghost := range g.ghosts {
+		ghostX, ghostY := physics.PosToTile(ghost.Pos)
+		if ghostX == playerX && ghostY == playerY && !g.shieldBlocks(ghost) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotTurn copies the state the next turn is about to change
+func (g *Game) snapshotTurn() turnSnapshot {
+	snap := turnSnapshot{
+		player:           *g.player,
+		energy:           append([]float64(nil), g.turns.energy...),
+		itemsSpawned:     maps.Clone(g.itemsSpawned),
+		recentItems:      append([]string(nil), g.recentItems...),
+		respawns:         append([]itemRespawn(nil), g.respawns...),
+		spawner:          g.spawner.Clone(),
+		reservations:     g.level.Reservations.Clone(),
+		rngState:         *g.rngState,
+		powerups:         g.powerups.Snapshot(),
+		scorer:           *g.scorer,
+		pelletsCollected: g.pelletsCollected,
+		levelTicks:       g.levelTicks,
+		ghostsEaten:      g.ghostsEaten,
+		fruitCollected:   g.fruitCollected,
+		fruitMissed:      g.fruitMissed,
+		reachedExit:      g.reachedExit,
+		moves:            g.turns.moves,
+		caught:           g.turns.caught,
+	}
+	for _, ghost := range g.ghosts {
+		snap.ghosts = append(snap.ghosts, *ghost)
+	}
+	for _, item := range g.level.Items {
+		snap.items = append(snap.items, *item)
+	}
+	for _, row := range g.level.Grid {
+		snap.grid = append(snap.grid, append([]model.Tile(nil), row...))
+	}
+	return snap
+}
+
+// undoTurn restores the state from before the last turn
+func (g *Game) undoTurn() {
+	if len(g.turns.history) == 0 {
+		return
+	}
+
+	snap := g.turns.history[len(g.turns.history)-1]
+	g.turns.history = g.turns.history[:len(g.turns.history)-1]
+
+	*g.player = snap.player
+	for i, ghost := range g.ghosts {
+		*ghost = snap.ghosts[i]
+	}
+	g.level.Grid = snap.grid
+	g.level.Items = nil
+	for _, item := range snap.items {
+		g.level.AddItem(&item)
+	}
+	g.itemsSpawned = snap.itemsSpawned
+	g.recentItems = snap.recentItems
+	g.respawns = snap.respawns
+	g.spawner = snap.spawner
+	g.level.Reservations = snap.reservations
+	*g.rngState = snap.rngState
+	g.powerups.Restore(snap.powerups)
+	*g.scorer = snap.scorer
+	g.pelletsCollected = snap.pelletsCollected
+	g.levelTicks = snap.levelTicks
+	g.ghostsEaten = snap.ghostsEaten
+	g.fruitCollected = snap.fruitCollected
+	g.fruitMissed = snap.fruitMissed
+	g.reachedExit = snap.reachedExit
+	g.turns.energy = snap.energy
+	g.turns.moves = snap.moves
+	g.turns.caught = snap.caught
+	g.objectives = objective.Evaluate(g.levelRules.Objectives, g.progress())
+}
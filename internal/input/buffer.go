@@ -0,0 +1,37 @@
+package input
+
+import "github.com/vladyslavpavlenko/pacman/internal/types"
+
+// TurnBuffer remembers a requested turn for a number of ticks so it can fire
+// at the next junction even if the key was released slightly too early
+type TurnBuffer struct {
+	window int
+	dir    types.Vector
+	ticks  int
+}
+
+// NewTurnBuffer creates a buffer that keeps a queued turn for window ticks
+func NewTurnBuffer(window int) *TurnBuffer {
+	return &TurnBuffer{window: window}
+}
+
+// Queue requests a turn. The turn is offered on the next tick and for window
+// more ticks after that.
+func (b *TurnBuffer) Queue(dir types.Vector) {
+	b.dir = dir
+	b.ticks = b.window + 1
+}
+
+// Next returns the queued turn and counts down its remaining ticks
+func (b *TurnBuffer) Next() (types.Vector, bool) {
+	if b.ticks <= 0 {
+		return types.Vector{}, false
+	}
+	b.ticks--
+	return b.dir, true
+}
+
+// Clear drops the queued turn
+func (b *TurnBuffer) Clear() {
+	b.ticks = 0
+}
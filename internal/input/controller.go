@@ -0,0 +1,73 @@
+package input
+
+import (
+	"slices"
+
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+var directions = map[Action]types.Vector{
+	ActionUp:    {X: 0, Y: -1},
+	ActionDown:  {X: 0, Y: 1},
+	ActionLeft:  {X: -1, Y: 0},
+	ActionRight: {X: 1, Y: 0},
+}
+
+// Controller turns held direction keys into a single requested direction,
+// giving priority to the most recently pressed one
+type Controller struct {
+	keymap *Keymap
+	held   []Action // held directions in press order, most recent last
+}
+
+// NewController creates a controller reading from the given keymap
+func NewController(keymap *Keymap) *Controller {
+	return &Controller{keymap: keymap}
+}
+
+// Keymap returns the keymap the controller reads from
+func (c *Controller) Keymap() *Keymap {
+	return c.keymap
+}
+
+// Update refreshes the set of held directions; call it once per frame
+func (c *Controller) Update() {
+	for _, action := range []Action{ActionUp, ActionDown, ActionLeft, ActionRight} {
+		if c.keymap.JustPressed(action) || (c.keymap.Pressed(action) && !slices.Contains(c.held, action)) {
+			c.release(action)
+			c.held = append(c.held, action)
+		} else if !c.keymap.Pressed(action) {
+			c.release(action)
+		}
+	}
+}
+
+// Direction returns the most recently pressed direction that is still held
+func (c *Controller) Direction() (types.Vector, bool) {
+	if len(c.held) == 0 {
+		return types.Vector{}, false
+	}
+	return directions[c.held[len(c.held)-1]], true
+}
+
+// JustPressedDirection returns a direction whose key went down this frame,
+// for play that moves once per key press
+func (c *Controller) JustPressedDirection() (types.Vector, bool) {
+	for _, action := range []Action{ActionUp, ActionDown, ActionLeft, ActionRight} {
+		if c.keymap.JustPressed(action) {
+			return directions[action], true
+		}
+	}
+	return types.Vector{}, false
+}
+
+// Reset forgets all held directions
+func (c *Controller) Reset() {
+	c.held = c.held[:0]
+}
+
+func (c *Controller) release(action Action) {
+	if i := slices.Index(c.held, action); i >= 0 {
+		c.held = slices.Delete(c.held, i, i+1)
+	}
+}
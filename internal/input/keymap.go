@@ -0,0 +1,108 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is a named game command that physical keys are bound to
+type Action string
+
+const (
+	ActionUp      Action = "up"
+	ActionDown    Action = "down"
+	ActionLeft    Action = "left"
+	ActionRight   Action = "right"
+	ActionRestart Action = "restart"
+	ActionDebug   Action = "debug"
+	ActionBack    Action = "back"
+	ActionFaster  Action = "faster"
+	ActionSlower  Action = "slower"
+	ActionUndo    Action = "undo"
+	ActionNext    Action = "next"
+)
+
+// Actions lists every action that can be bound
+var Actions = []Action{ActionUp, ActionDown, ActionLeft, ActionRight, ActionRestart, ActionDebug, ActionBack, ActionFaster, ActionSlower, ActionUndo, ActionNext}
+
+// Keymap maps actions to the physical keys that trigger them
+type Keymap struct {
+	keys map[Action][]ebiten.Key
+}
+
+// NewKeymap builds a keymap from action names to key names as used by ebiten
+// (for example "ArrowUp", "W" or "F1")
+func NewKeymap(bindings map[string][]string) (*Keymap, error) {
+	km := &Keymap{keys: make(map[Action][]ebiten.Key)}
+
+	var errs []error
+	boundTo := make(map[ebiten.Key]Action)
+	for name, keyNames := range bindings {
+		action := Action(name)
+		if !slices.Contains(Actions, action) {
+			errs = append(errs, fmt.Errorf("controls: unknown action %q, want one of %v", name, Actions))
+			continue
+		}
+		for _, keyName := range keyNames {
+			var key ebiten.Key
+			if err := key.UnmarshalText([]byte(keyName)); err != nil {
+				errs = append(errs, fmt.Errorf("controls.%s: unknown key %q", name, keyName))
+				continue
+			}
+			// One key triggering two actions is what rebinding is meant to fix
+			if other, ok := boundTo[key]; ok && other != action {
+				first, second := min(other, action), max(other, action)
+				errs = append(errs, fmt.Errorf("controls: key %q is bound to both %s and %s", keyName, first, second))
+				continue
+			}
+			boundTo[key] = action
+			km.keys[action] = append(km.keys[action], key)
+		}
+	}
+
+	for _, action := range Actions {
+		if len(km.keys[action]) == 0 {
+			errs = append(errs, fmt.Errorf("controls.%s: no keys bound", action))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// Keys returns the names of the keys bound to the action for help text, for
+// example "Enter/Space"
+func (km *Keymap) Keys(action Action) string {
+	names := make([]string, len(km.keys[action]))
+	for i, key := range km.keys[action] {
+		names[i] = key.String()
+	}
+	return strings.Join(names, "/")
+}
+
+// Pressed reports whether any key bound to the action is held down
+func (km *Keymap) Pressed(action Action) bool {
+	for _, key := range km.keys[action] {
+		if ebiten.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// JustPressed reports whether any key bound to the action was pressed this frame
+func (km *Keymap) JustPressed(action Action) bool {
+	for _, key := range km.keys[action] {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
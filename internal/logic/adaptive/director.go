@@ -0,0 +1,120 @@
+package adaptive
+
+import (
+	"math"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+)
+
+// Adjustment describes how the base difficulty should currently be modified
+type Adjustment struct {
+	Intensity  float64 // -1 (much easier) .. 1 (much harder)
+	SpeedScale float64 // multiplier applied to ghost speeds
+	TierShift  int     // offset applied to the preset's algorithm mix
+}
+
+// Director watches player performance and adjusts difficulty within bounds
+type Director struct {
+	bounds      config.AdaptiveBounds
+	tickRate    int
+	intensity   float64
+	windowStart int
+	lastCatch   int
+	catches     int
+	pellets     int
+	nearMisses  int
+	current     Adjustment
+}
+
+// New creates a director for the given simulation tick rate
+func New(bounds config.AdaptiveBounds, tickRate int) *Director {
+	d := &Director{
+		bounds:   bounds,
+		tickRate: tickRate,
+	}
+	d.current = d.adjustmentFor(0)
+	return d
+}
+
+// RecordPellet registers a collected pellet
+func (d *Director) RecordPellet() {
+	d.pellets++
+}
+
+// RecordCatch registers the player being caught by a ghost
+func (d *Director) RecordCatch(tick int) {
+	d.catches++
+	d.lastCatch = tick
+}
+
+// RecordNearMiss registers a ghost passing close to the player without a catch
+func (d *Director) RecordNearMiss() {
+	d.nearMisses++
+}
+
+// Current returns the adjustment currently in effect
+func (d *Director) Current() Adjustment {
+	return d.current
+}
+
+// Update evaluates the player's performance once per window and returns true
+// when the adjustment changed
+func (d *Director) Update(tick int) bool {
+	elapsed := tick - d.windowStart
+	if elapsed < d.ticks(d.bounds.WindowTime) {
+		return false
+	}
+
+	seconds := float64(elapsed) / float64(d.tickRate)
+	pelletRate := float64(d.pellets) / seconds
+
+	// Positive performance means the player is doing well and can take more
+	performance := pelletRate/d.bounds.TargetPelletRate - 1
+	performance -= 0.5 * float64(d.catches)
+	if d.catches == 0 && tick-d.lastCatch >= d.ticks(d.bounds.LongSurvivalTime) {
+		performance += 0.25
+	}
+	if tolerance := d.bounds.NearMissTolerance; d.nearMisses > tolerance {
+		performance -= 0.1 * float64(d.nearMisses-tolerance)
+	}
+	performance = clamp(performance, -1, 1)
+
+	d.intensity = clamp(d.intensity+performance*d.bounds.IntensityStep, -1, 1)
+
+	d.windowStart = tick
+	d.catches = 0
+	d.pellets = 0
+	d.nearMisses = 0
+
+	next := d.adjustmentFor(d.intensity)
+	changed := next.SpeedScale != d.current.SpeedScale || next.TierShift != d.current.TierShift
+	d.current = next
+	return changed
+}
+
+// adjustmentFor maps an intensity to concrete parameters within bounds
+func (d *Director) adjustmentFor(intensity float64) Adjustment {
+	speedScale := 1.0
+	if intensity > 0 {
+		speedScale += intensity * (d.bounds.MaxSpeedScale - 1)
+	} else {
+		speedScale += intensity * (1 - d.bounds.MinSpeedScale)
+	}
+
+	tierShift := int(math.Round(intensity * float64(d.bounds.MaxTierShift)))
+
+	return Adjustment{
+		Intensity:  intensity,
+		SpeedScale: math.Round(speedScale*100) / 100,
+		TierShift:  tierShift,
+	}
+}
+
+// ticks converts seconds to simulation ticks
+func (d *Director) ticks(seconds float64) int {
+	return int(math.Round(seconds * float64(d.tickRate)))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,107 @@
+package adaptive
+
+import (
+	"testing"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+)
+
+const tickRate = 60
+
+// window is one evaluation window of play
+type window struct {
+	pellets, catches, nearMisses int
+}
+
+// play feeds the director the given windows back to back, catches landing at
+// the start of their window, and returns the adjustment after the last one
+func play(t *testing.T, d *Director, windows []window) Adjustment {
+	t.Helper()
+	windowTicks := d.ticks(d.bounds.WindowTime)
+	for i, w := range windows {
+		start := i * windowTicks
+		for range w.pellets {
+			d.RecordPellet()
+		}
+		for range w.catches {
+			d.RecordCatch(start)
+		}
+		for range w.nearMisses {
+			d.RecordNearMiss()
+		}
+		if d.Update(start + windowTicks - 1) {
+			t.Fatalf("window %d evaluated a tick early", i+1)
+		}
+		d.Update(start + windowTicks)
+	}
+	return d.Current()
+}
+
+func TestDirectorStartsNeutral(t *testing.T) {
+	adj := New(config.DefaultRules().Adaptive, tickRate).Current()
+	if adj.Intensity != 0 || adj.SpeedScale != 1 || adj.TierShift != 0 {
+		t.Errorf("Current = %+v, want the preset unchanged", adj)
+	}
+}
+
+func TestDirectorUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []window
+		harder  bool
+	}{
+		{"pellets quickly and no catches", []window{{pellets: 30}}, true},
+		{"caught and no pellets", []window{{catches: 1}}, false},
+		{"slow pellets", []window{{pellets: 5}}, false},
+		{"close calls outweigh a good pace", []window{{pellets: 16, nearMisses: 20}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adj := play(t, New(config.DefaultRules().Adaptive, tickRate), tt.windows)
+			if harder := adj.Intensity > 0; harder != tt.harder {
+				t.Errorf("Intensity = %v, want harder = %v", adj.Intensity, tt.harder)
+			}
+			if harder := adj.SpeedScale > 1; harder != tt.harder {
+				t.Errorf("SpeedScale = %v, want harder = %v", adj.SpeedScale, tt.harder)
+			}
+		})
+	}
+}
+
+func TestDirectorStaysWithinBounds(t *testing.T) {
+	bounds := config.DefaultRules().Adaptive
+	tests := []struct {
+		name   string
+		window window
+		speed  float64
+		tier   int
+	}{
+		{"always doing well", window{pellets: 100}, bounds.MaxSpeedScale, bounds.MaxTierShift},
+		{"always struggling", window{catches: 3}, bounds.MinSpeedScale, -bounds.MaxTierShift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows := make([]window, 20)
+			for i := range windows {
+				windows[i] = tt.window
+			}
+			adj := play(t, New(bounds, tickRate), windows)
+			if adj.SpeedScale != tt.speed {
+				t.Errorf("SpeedScale = %v, want %v", adj.SpeedScale, tt.speed)
+			}
+			if adj.TierShift != tt.tier {
+				t.Errorf("TierShift = %d, want %d", adj.TierShift, tt.tier)
+			}
+		})
+	}
+}
+
+func TestDirectorIntensityStep(t *testing.T) {
+	bounds := config.DefaultRules().Adaptive
+	adj := play(t, New(bounds, tickRate), []window{{pellets: 1000}})
+	if adj.Intensity != bounds.IntensityStep {
+		t.Errorf("Intensity after one window = %v, want at most one step of %v", adj.Intensity, bounds.IntensityStep)
+	}
+}
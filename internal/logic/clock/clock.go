@@ -0,0 +1,107 @@
+package clock
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	MinSpeed   = 0.5
+	MaxSpeed   = 3.0
+	maxElapsed = 250 * time.Millisecond // longest real-time gap simulated at once
+)
+
+// SpeedSteps are the game speeds offered when stepping the speed up or down
+var SpeedSteps = []float64{0.5, 0.75, 1, 1.5, 2, 3}
+
+// Clock converts elapsed real time into a whole number of fixed simulation
+// ticks, so the simulation runs at the same rate whatever the frame rate
+type Clock struct {
+	tickRate int
+	speed    float64
+	pending  float64 // simulated seconds not yet consumed by a tick
+	last     time.Time
+}
+
+// New creates a clock ticking tickRate times per simulated second, running
+// at the given game speed
+func New(tickRate int, speed float64) *Clock {
+	c := &Clock{tickRate: tickRate}
+	c.SetSpeed(speed)
+	return c
+}
+
+// TickRate returns the number of simulation ticks per simulated second
+func (c *Clock) TickRate() int {
+	return c.tickRate
+}
+
+// Speed returns the game speed multiplier
+func (c *Clock) Speed() float64 {
+	return c.speed
+}
+
+// SetSpeed sets the game speed multiplier, clamped to [MinSpeed, MaxSpeed]
+func (c *Clock) SetSpeed(speed float64) {
+	c.speed = math.Max(MinSpeed, math.Min(MaxSpeed, speed))
+}
+
+// StepSpeed moves the game speed to the next faster (up) or slower step
+func (c *Clock) StepSpeed(up bool) {
+	if up {
+		for _, step := range SpeedSteps {
+			if step > c.speed {
+				c.SetSpeed(step)
+				return
+			}
+		}
+		return
+	}
+	for i := len(SpeedSteps) - 1; i >= 0; i-- {
+		if SpeedSteps[i] < c.speed {
+			c.SetSpeed(SpeedSteps[i])
+			return
+		}
+	}
+}
+
+// Advance accounts for the real time passed since the previous call and
+// returns how many ticks to simulate now
+func (c *Clock) Advance(now time.Time) int {
+	if c.last.IsZero() {
+		c.last = now
+		return 0
+	}
+
+	elapsed := min(now.Sub(c.last), maxElapsed)
+	c.last = now
+
+	c.pending += elapsed.Seconds() * c.speed
+	tick := 1 / float64(c.tickRate)
+
+	ticks := int(c.pending / tick)
+	c.pending -= float64(ticks) * tick
+	return ticks
+}
+
+// Alpha returns how far the simulation has progressed toward the next tick,
+// from 0 (just ticked) up to but not including 1
+func (c *Clock) Alpha() float64 {
+	return math.Min(c.pending*float64(c.tickRate), 1)
+}
+
+// Reset forgets the real time passed so far, for example after a pause
+func (c *Clock) Reset() {
+	c.pending = 0
+	c.last = time.Time{}
+}
+
+// Ticks converts a duration in simulated seconds to ticks
+func (c *Clock) Ticks(seconds float64) int {
+	return int(math.Round(seconds * float64(c.tickRate)))
+}
+
+// Seconds converts a number of ticks to simulated seconds
+func (c *Clock) Seconds(ticks int) float64 {
+	return float64(ticks) / float64(c.tickRate)
+}
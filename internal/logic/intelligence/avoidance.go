@@ -0,0 +1,58 @@
+package intelligence
+
+import (
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// ReserveTiles refills the level's reservation table with the tiles the ghosts
+// stand on and, where still free, the tiles they are about to enter
+func ReserveTiles(ghosts []*model.Ghost, lvl *model.Level) {
+	if lvl.Reservations == nil {
+		return
+	}
+
+	lvl.Reservations.Clear()
+	for _, ghost := range ghosts {
+		lvl.Reservations.Reserve(ghostTile(ghost), ghost)
+	}
+	for _, ghost := range ghosts {
+		claim(ghost, ghost.Dir, lvl)
+	}
+}
+
+// avoid adds the reservation penalty to dist so ghosts prefer tiles no other
+// ghost holds
+func avoid(ghost *model.Ghost, dist DistanceFunc, lvl *model.Level) DistanceFunc {
+	if lvl.Reservations == nil {
+		return dist
+	}
+	return func(tileX, tileY int) int {
+		return dist(tileX, tileY) + lvl.Reservations.Penalty(types.Tile{X: tileX, Y: tileY}, ghost)
+	}
+}
+
+// claim reserves the tile a ghost is about to enter in dir, dropping its
+// earlier claims apart from the tile it stands on
+func claim(ghost *model.Ghost, dir types.Vector, lvl *model.Level) {
+	if lvl.Reservations == nil {
+		return
+	}
+
+	current := ghostTile(ghost)
+	lvl.Reservations.Release(ghost, current)
+	if dir.Eq(types.Vector{}) {
+		return
+	}
+
+	next := types.Tile{X: current.X + int(dir.X), Y: current.Y + int(dir.Y)}
+	if lvl.CanWalk(next.X, next.Y) {
+		lvl.Reservations.Reserve(next, ghost)
+	}
+}
+
+func ghostTile(ghost *model.Ghost) types.Tile {
+	tileX, tileY := physics.PosToTile(ghost.Pos)
+	return types.Tile{X: tileX, Y: tileY}
+}
@@ -0,0 +1,98 @@
+package intelligence
+
+import (
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// directionPriority is the arcade tie-break order: up, left, down, right
+var directionPriority = []types.Vector{{X: 0, Y: -1}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}}
+
+// Exits returns the directions a ghost travelling in dir may take from a tile,
+// in priority order. Ghosts never turn back unless the tile is a dead end, and
+// never turn upward inside a no-up zone unless there is no other way out.
+func Exits(tileX, tileY int, dir types.Vector, lvl *model.Level) []types.Vector {
+	exits := exitsFrom(tileX, tileY, dir, lvl, lvl.IsNoUpZone(tileX, tileY))
+	if len(exits) == 0 {
+		exits = exitsFrom(tileX, tileY, dir, lvl, false)
+	}
+	if len(exits) == 0 && !dir.Eq(types.Vector{}) {
+		reverse := dir.Mul(-1)
+		if lvl.CanWalk(tileX+int(reverse.X), tileY+int(reverse.Y)) {
+			exits = append(exits, reverse)
+		}
+	}
+	return exits
+}
+
+func exitsFrom(tileX, tileY int, dir types.Vector, lvl *model.Level, noUp bool) []types.Vector {
+	reverse := dir.Mul(-1)
+
+	var exits []types.Vector
+	for _, d := range directionPriority {
+		if !dir.Eq(types.Vector{}) && d.Eq(reverse) {
+			continue
+		}
+		if noUp && d.Y < 0 && !d.Eq(dir) {
+			continue
+		}
+		if lvl.CanWalk(tileX+int(d.X), tileY+int(d.Y)) {
+			exits = append(exits, d)
+		}
+	}
+	return exits
+}
+
+// decisionPoint applies the movement rules shared by every strategy. It carries
+// out forced reversals, waits for the tile center and follows corridors and
+// corners on its own. It returns the exits to choose from and true only when
+// the ghost stands on a junction and its strategy has to decide.
+func decisionPoint(ghost *model.Ghost, lvl *model.Level) ([]types.Vector, bool) {
+	if ghost.ReversePending {
+		ghost.ReversePending = false
+		if !ghost.Dir.Eq(types.Vector{}) {
+			ghost.Dir = ghost.Dir.Mul(-1)
+			ghost.WantDir = ghost.Dir
+			return nil, false
+		}
+	}
+
+	// Decide once per tile while approaching its center, so fast ghosts that
+	// step over the exact center still turn at every junction
+	tileX, tileY := physics.PosToTile(ghost.Pos)
+	tile := types.Tile{X: tileX, Y: tileY}
+	if !ghost.Dir.Eq(types.Vector{}) {
+		if !physics.NearCenter(ghost.Pos) || tile == ghost.DecisionTile {
+			return nil, false
+		}
+	}
+	ghost.DecisionTile = tile
+
+	exits := Exits(tileX, tileY, ghost.Dir, lvl)
+
+	switch len(exits) {
+	case 0:
+		return nil, false
+	case 1:
+		ghost.WantDir = exits[0]
+		claim(ghost, ghost.WantDir, lvl)
+		return nil, false
+	}
+	return exits, true
+}
+
+// bestExit returns the exit leading closest to the target, preferring earlier
+// exits on ties
+func bestExit(exits []types.Vector, tileX, tileY int, dist DistanceFunc) types.Vector {
+	best := exits[0]
+	minDistance := 1 << 30
+	for _, dir := range exits {
+		distance := dist(tileX+int(dir.X), tileY+int(dir.Y))
+		if distance < minDistance {
+			minDistance = distance
+			best = dir
+		}
+	}
+	return best
+}
@@ -2,7 +2,7 @@ package intelligence
 
 import (
 	"math"
-	"math/rand"
+	"math/rand/v2"
 
 	"github.com/vladyslavpavlenko/pacman/internal/config"
 	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
@@ -30,7 +30,7 @@ func NewDistanceMap(width, height int) *DistanceMap {
 	return dm
 }
 
-func (dm *DistanceMap) BuildBFS(targetPos types.Vector, lvl *model.Level) {
+func (dm *DistanceMap) BuildBFS(targetPos types.Point, lvl *model.Level) {
 	const infinity = 1 << 30
 	for y := 0; y < dm.height; y++ {
 		for x := 0; x < dm.width; x++ {
@@ -87,325 +87,107 @@ func (dm *DistanceMap) GetDistance(tileX, tileY int) int {
 	return dm.distances[tileY][tileX]
 }
 
-type candidate struct {
-	dir      types.Vector
-	distance int
-}
-
-func GhostAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level, difficulty config.Difficulty) {
-	availableLevels := getAvailableSkillLevels(difficulty)
-
-	behaviorIndex := rand.Intn(len(availableLevels))
-	chosenBehavior := availableLevels[behaviorIndex]
+// DistanceFunc measures how far a tile is from a ghost's target
+type DistanceFunc func(tileX, tileY int) int
 
-	switch chosenBehavior {
-	case config.GhostSkillLevelDumb:
-		dumbGhostAI(ghost, lvl)
-	case config.GhostSkillLevelSlow:
-		slowGhostAI(ghost, distanceMap, lvl)
-	case config.GhostSkillLevelNormal:
-		normalGhostAI(ghost, distanceMap, lvl)
-	case config.GhostSkillLevelSmart:
-		smartGhostAI(ghost, distanceMap, lvl)
-	default:
-		normalGhostAI(ghost, distanceMap, lvl)
+// ManhattanTo returns a DistanceFunc measuring grid distance to the given tile
+func ManhattanTo(targetX, targetY int) DistanceFunc {
+	return func(tileX, tileY int) int {
+		return int(math.Abs(float64(tileX-targetX)) + math.Abs(float64(tileY-targetY)))
 	}
 }
 
-// getAvailableSkillLevels returns skill levels available for a given difficulty
-func getAvailableSkillLevels(difficulty config.Difficulty) []config.GhostLevel {
-	switch difficulty {
-	case config.DifficultyEasy:
-		return []config.GhostLevel{
-			config.GhostSkillLevelDumb,
-			config.GhostSkillLevelDumb,
-			config.GhostSkillLevelSlow,
-			config.GhostSkillLevelSlow,
-			config.GhostSkillLevelNormal,
-		}
-	case config.DifficultyMedium:
-		return []config.GhostLevel{
-			config.GhostSkillLevelSlow,
-			config.GhostSkillLevelSlow,
-			config.GhostSkillLevelNormal,
-			config.GhostSkillLevelNormal,
-			config.GhostSkillLevelSmart,
-		}
-	case config.DifficultyHard:
-		return []config.GhostLevel{
-			config.GhostSkillLevelNormal,
-			config.GhostSkillLevelNormal,
-			config.GhostSkillLevelSmart,
-			config.GhostSkillLevelSmart,
-			config.GhostSkillLevelSlow,
-		}
-	default:
-		return []config.GhostLevel{config.GhostSkillLevelNormal}
-	}
+// GhostAI chases the player along the BFS distance map using the ghost's own skill level
+func GhostAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand) {
+	steer(ghost, distanceMap.GetDistance, lvl, rng)
 }
 
-// dumbGhostAI implements random movement (ignores player)
-func dumbGhostAI(ghost *model.Entity, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
+// steer moves a ghost toward the target measured by dist the way its skill
+// level allows, drawing any random choices from rng
+func steer(ghost *model.Ghost, dist DistanceFunc, lvl *model.Level, rng *rand.Rand) {
+	exits, decide := decisionPoint(ghost, lvl)
+	if !decide {
 		return
 	}
 
-	if ghost.Dir.Eq(types.Vector{}) {
-		tileX, tileY := physics.PosToTile(ghost.Pos)
-		for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-			nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-			if lvl.CanWalk(nextX, nextY) {
-				ghost.Dir = dir
-				ghost.WantDir = dir
-				ghost.Pos = physics.TileCenter(tileX, tileY)
-				return
-			}
-		}
-	}
-
 	tileX, tileY := physics.PosToTile(ghost.Pos)
+	dist = avoid(ghost, dist, lvl)
 
-	var directions []types.Vector
-	checkDirection := func(dx, dy float64) {
-		nextX, nextY := tileX+int(dx), tileY+int(dy)
-		if lvl.CanWalk(nextX, nextY) {
-			directions = append(directions, types.Vector{X: dx, Y: dy})
-		}
-	}
-
-	checkDirection(1, 0)
-	checkDirection(-1, 0)
-	checkDirection(0, 1)
-	checkDirection(0, -1)
-
-	if len(directions) == 0 {
-		if !ghost.Dir.Eq(types.Vector{}) {
-			nextX, nextY := tileX+int(ghost.Dir.X), tileY+int(ghost.Dir.Y)
-			if lvl.CanWalk(nextX, nextY) {
-				ghost.Pos = physics.TileCenter(tileX, tileY)
-				return
-			}
-		}
-		for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-			nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-			if lvl.CanWalk(nextX, nextY) {
-				ghost.Dir = dir
-				ghost.WantDir = dir
-				ghost.Pos = physics.TileCenter(tileX, tileY)
-				return
-			}
-		}
-		ghost.Dir = types.Vector{}
-		ghost.WantDir = types.Vector{}
-		return
+	switch ghost.SkillLevel {
+	case config.GhostSkillLevelDumb:
+		ghost.WantDir = dumbGhostAI(exits, rng)
+	case config.GhostSkillLevelSlow:
+		ghost.WantDir = slowGhostAI(exits, tileX, tileY, dist, rng)
+	case config.GhostSkillLevelNormal:
+		ghost.WantDir = normalGhostAI(exits, tileX, tileY, dist)
+	case config.GhostSkillLevelSmart:
+		ghost.WantDir = smartGhostAI(exits, tileX, tileY, dist, lvl)
+	case config.GhostSkillLevelGenius:
+		ghost.WantDir = geniusGhostAI(exits, tileX, tileY, dist, lvl)
+	default:
+		ghost.WantDir = normalGhostAI(exits, tileX, tileY, dist)
 	}
+	claim(ghost, ghost.WantDir, lvl)
+}
 
-	chosen := directions[rand.Intn(len(directions))]
-	ghost.WantDir = chosen
+// dumbGhostAI implements random movement (ignores player)
+func dumbGhostAI(exits []types.Vector, rng *rand.Rand) types.Vector {
+	return exits[rng.IntN(len(exits))]
 }
 
 // slowGhostAI implements AI that follows player but makes mistakes
-func slowGhostAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	if rand.Float32() < 0.3 {
-		dumbGhostAI(ghost, lvl)
-		return
+func slowGhostAI(exits []types.Vector, tileX, tileY int, dist DistanceFunc, rng *rand.Rand) types.Vector {
+	if rng.Float32() < 0.3 {
+		return dumbGhostAI(exits, rng)
 	}
 
-	normalGhostAI(ghost, distanceMap, lvl)
+	return normalGhostAI(exits, tileX, tileY, dist)
 }
 
-// smartGhostAI implements optimized pathfinding with some prediction
-func smartGhostAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	tileX, tileY := physics.PosToTile(ghost.Pos)
-
-	var options []candidate
-
-	checkDirection := func(dx, dy float64) {
-		nextX, nextY := tileX+int(dx), tileY+int(dy)
-		if lvl.CanWalk(nextX, nextY) {
-			distance := distanceMap.GetDistance(nextX, nextY)
-			exitCount := 0
-			for _, checkDir := range []types.Tile{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-				if lvl.CanWalk(nextX+checkDir.X, nextY+checkDir.Y) {
-					exitCount++
-				}
-			}
-			if exitCount == 1 {
-				distance += 5
-			}
-			options = append(options, candidate{
-				dir:      types.Vector{X: dx, Y: dy},
-				distance: distance,
-			})
-		}
-	}
-
-	checkDirection(1, 0)  // right
-	checkDirection(-1, 0) // left
-	checkDirection(0, 1)  // down
-	checkDirection(0, -1) // up
-
-	if len(options) == 0 {
-		ghost.Dir = types.Vector{}
-		return
-	}
-
-	minDistance := 1 << 30
-	for _, option := range options {
-		if option.distance < minDistance {
-			minDistance = option.distance
-		}
-	}
+// normalGhostAI implements standard greedy pathfinding
+func normalGhostAI(exits []types.Vector, tileX, tileY int, dist DistanceFunc) types.Vector {
+	return bestExit(exits, tileX, tileY, dist)
+}
 
-	var bestOptions []candidate
-	for _, option := range options {
-		if option.distance == minDistance {
-			bestOptions = append(bestOptions, option)
+// smartGhostAI implements optimized pathfinding that avoids dead ends
+func smartGhostAI(exits []types.Vector, tileX, tileY int, dist DistanceFunc, lvl *model.Level) types.Vector {
+	return bestExit(exits, tileX, tileY, func(x, y int) int {
+		distance := dist(x, y)
+		if exitCount(x, y, lvl) == 1 {
+			distance += 5
 		}
-	}
-
-	chosen := bestOptions[rand.Intn(len(bestOptions))]
-	ghost.WantDir = chosen.dir
+		return distance
+	})
 }
 
 // geniusGhostAI implements advanced AI with player movement prediction
-func geniusGhostAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	tileX, tileY := physics.PosToTile(ghost.Pos)
-
-	var options []candidate
-
-	checkDirection := func(dx, dy float64) {
-		nextX, nextY := tileX+int(dx), tileY+int(dy)
-		if lvl.CanWalk(nextX, nextY) {
-			distance := distanceMap.GetDistance(nextX, nextY)
-
-			if distance <= 3 {
-				distance -= 2
-			}
+func geniusGhostAI(exits []types.Vector, tileX, tileY int, dist DistanceFunc, lvl *model.Level) types.Vector {
+	return bestExit(exits, tileX, tileY, func(x, y int) int {
+		distance := dist(x, y)
 
-			exitCount := 0
-			for _, checkDir := range []types.Tile{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-				if lvl.CanWalk(nextX+checkDir.X, nextY+checkDir.Y) {
-					exitCount++
-				}
-			}
-			if exitCount == 1 {
-				distance += 10
-			} else if exitCount >= 3 {
-				distance -= 1
-			}
-
-			options = append(options, candidate{
-				dir:      types.Vector{X: dx, Y: dy},
-				distance: distance,
-			})
+		if distance <= 3 {
+			distance -= 2
 		}
-	}
-
-	checkDirection(1, 0)  // right
-	checkDirection(-1, 0) // left
-	checkDirection(0, 1)  // down
-	checkDirection(0, -1) // up
-
-	if len(options) == 0 {
-		ghost.Dir = types.Vector{}
-		return
-	}
 
-	minDistance := 1 << 30
-	for _, option := range options {
-		if option.distance < minDistance {
-			minDistance = option.distance
+		count := exitCount(x, y, lvl)
+		if count == 1 {
+			distance += 10
+		} else if count >= 3 {
+			distance -= 1
 		}
-	}
-
-	var bestOptions []candidate
-	for _, option := range options {
-		if option.distance == minDistance {
-			bestOptions = append(bestOptions, option)
-		}
-	}
-
-	if len(bestOptions) > 1 {
-		for _, option := range bestOptions {
-			if option.dir.Eq(ghost.Dir) {
-				ghost.WantDir = option.dir
-				return
-			}
-		}
-	}
-
-	chosen := bestOptions[rand.Intn(len(bestOptions))]
-	ghost.WantDir = chosen.dir
+		return distance
+	})
 }
 
-// normalGhostAI implements standard BFS pathfinding
-func normalGhostAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	tileX, tileY := physics.PosToTile(ghost.Pos)
-
-	var options []candidate
-
-	checkDirection := func(dx, dy float64) {
-		nextX, nextY := tileX+int(dx), tileY+int(dy)
-		if lvl.CanWalk(nextX, nextY) {
-			distance := distanceMap.GetDistance(nextX, nextY)
-			options = append(options, candidate{
-				dir:      types.Vector{X: dx, Y: dy},
-				distance: distance,
-			})
-		}
-	}
-
-	checkDirection(1, 0)
-	checkDirection(-1, 0)
-	checkDirection(0, 1)
-	checkDirection(0, -1)
-
-	if len(options) == 0 {
-		if !ghost.Dir.Eq(types.Vector{}) {
-			nextX, nextY := tileX+int(ghost.Dir.X), tileY+int(ghost.Dir.Y)
-			if lvl.CanWalk(nextX, nextY) {
-				ghost.Pos = physics.TileCenter(tileX, tileY)
-				return
-			}
-		}
-		ghost.Dir = types.Vector{}
-		ghost.WantDir = types.Vector{}
-		return
-	}
-
-	minDistance := 1 << 30
-	for _, option := range options {
-		if option.distance < minDistance {
-			minDistance = option.distance
+// exitCount returns the number of walkable neighbours of a tile
+func exitCount(x, y int, lvl *model.Level) int {
+	count := 0
+	for _, checkDir := range []types.Tile{{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1}} {
+		if lvl.CanWalk(x+checkDir.X, y+checkDir.Y) {
+			count++
 		}
 	}
-
-	var bestOptions []candidate
-	for _, option := range options {
-		if option.distance == minDistance {
-			bestOptions = append(bestOptions, option)
-		}
-	}
-
-	chosen := bestOptions[rand.Intn(len(bestOptions))]
-	ghost.WantDir = chosen.dir
+	return count
 }
 
 // GhostAlgorithmType represents different AI algorithms for ghosts
@@ -441,154 +223,51 @@ func GetAlgorithmName(algorithm GhostAlgorithmType) string {
 }
 
 // ChaseAI implements direct pursuit of the player
-func ChaseAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level, playerPos types.Vector) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	tileX, tileY := physics.PosToTile(ghost.Pos)
+func ChaseAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand, playerPos types.Point) {
 	playerTileX, playerTileY := physics.PosToTile(playerPos)
-
-	var bestDir types.Vector
-	minDistance := math.MaxInt32
-
-	for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-		nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-		if lvl.CanWalk(nextX, nextY) {
-			// Manhattan distance: sum of absolute differences in x and y coordinates
-			// This gives the shortest path distance in a grid where movement is only horizontal/vertical
-			dist := int(math.Abs(float64(nextX-playerTileX)) + math.Abs(float64(nextY-playerTileY)))
-			if dist < minDistance {
-				minDistance = dist
-				bestDir = dir
-			}
-		}
-	}
-
-	if !bestDir.Eq(types.Vector{}) {
-		ghost.WantDir = bestDir
-	}
+	steer(ghost, ManhattanTo(playerTileX, playerTileY), lvl, rng)
 }
 
 // ScatterAI makes ghosts move to corners and patrol
-func ScatterAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level, cornerPos types.Vector) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	tileX, tileY := physics.PosToTile(ghost.Pos)
-	cornerTileX, cornerTileY := physics.PosToTile(cornerPos)
-
-	var bestDir types.Vector
-	minDistance := math.MaxInt32
-
-	for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-		nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-		if lvl.CanWalk(nextX, nextY) {
-			dist := int(math.Abs(float64(nextX-cornerTileX)) + math.Abs(float64(nextY-cornerTileY)))
-			if dist < minDistance {
-				minDistance = dist
-				bestDir = dir
-			}
-		}
-	}
-
-	if !bestDir.Eq(types.Vector{}) {
-		ghost.WantDir = bestDir
-	}
+func ScatterAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand, corner types.Tile) {
+	steer(ghost, ManhattanTo(corner.X, corner.Y), lvl, rng)
 }
 
 // FrightenedAI makes ghosts move randomly when player has power-up
-func FrightenedAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
+func FrightenedAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand) {
+	exits, decide := decisionPoint(ghost, lvl)
+	if !decide {
 		return
 	}
 
-	tileX, tileY := physics.PosToTile(ghost.Pos)
-	var validDirs []types.Vector
-
-	for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-		nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-		if lvl.CanWalk(nextX, nextY) {
-			validDirs = append(validDirs, dir)
-		}
-	}
-
-	if len(validDirs) > 0 {
-		chosen := validDirs[rand.Intn(len(validDirs))]
-		ghost.WantDir = chosen
-	}
+	ghost.WantDir = dumbGhostAI(exits, rng)
+	claim(ghost, ghost.WantDir, lvl)
 }
 
 // PatrolAI makes ghosts patrol between two points
-func PatrolAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level, patrolPoints []types.Vector) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
+func PatrolAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand, patrolPoints []types.Tile) {
 	if len(patrolPoints) < 2 {
-		FrightenedAI(ghost, distanceMap, lvl)
+		FrightenedAI(ghost, distanceMap, lvl, rng)
 		return
 	}
 
 	tileX, tileY := physics.PosToTile(ghost.Pos)
 
-	distToFirst := math.Abs(float64(tileX-int(patrolPoints[0].X))) + math.Abs(float64(tileY-int(patrolPoints[0].Y)))
-	distToSecond := math.Abs(float64(tileX-int(patrolPoints[1].X))) + math.Abs(float64(tileY-int(patrolPoints[1].Y)))
+	distToFirst := ManhattanTo(patrolPoints[0].X, patrolPoints[0].Y)(tileX, tileY)
+	distToSecond := ManhattanTo(patrolPoints[1].X, patrolPoints[1].Y)(tileX, tileY)
 
-	var targetPos types.Vector
+	target := patrolPoints[0]
 	if distToFirst < distToSecond {
-		targetPos = patrolPoints[1]
-	} else {
-		targetPos = patrolPoints[0]
-	}
-
-	targetTileX, targetTileY := physics.PosToTile(targetPos)
-	var bestDir types.Vector
-	minDistance := math.MaxInt32
-
-	for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-		nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-		if lvl.CanWalk(nextX, nextY) {
-			dist := int(math.Abs(float64(nextX-targetTileX)) + math.Abs(float64(nextY-targetTileY)))
-			if dist < minDistance {
-				minDistance = dist
-				bestDir = dir
-			}
-		}
+		target = patrolPoints[1]
 	}
 
-	if !bestDir.Eq(types.Vector{}) {
-		ghost.WantDir = bestDir
-	}
+	steer(ghost, ManhattanTo(target.X, target.Y), lvl, rng)
 }
 
 // AmbushAI tries to intercept the player by predicting their movement
-func AmbushAI(ghost *model.Entity, distanceMap *DistanceMap, lvl *model.Level, playerPos types.Vector, playerDir types.Vector) {
-	if !physics.AtCenter(ghost.Pos) && !ghost.Dir.Eq(types.Vector{}) {
-		return
-	}
-
-	predictedPos := playerPos.Add(playerDir.Mul(3))
+func AmbushAI(ghost *model.Ghost, distanceMap *DistanceMap, lvl *model.Level, rng *rand.Rand, playerPos types.Point, playerDir types.Vector) {
+	predictedPos := playerPos.Move(playerDir, 3*types.FixedOne)
 
-	tileX, tileY := physics.PosToTile(ghost.Pos)
 	predTileX, predTileY := physics.PosToTile(predictedPos)
-
-	var bestDir types.Vector
-	minDistance := math.MaxInt32
-
-	for _, dir := range []types.Vector{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
-		nextX, nextY := tileX+int(dir.X), tileY+int(dir.Y)
-		if lvl.CanWalk(nextX, nextY) {
-			dist := int(math.Abs(float64(nextX-predTileX)) + math.Abs(float64(nextY-predTileY)))
-			if dist < minDistance {
-				minDistance = dist
-				bestDir = dir
-			}
-		}
-	}
-
-	if !bestDir.Eq(types.Vector{}) {
-		ghost.WantDir = bestDir
-	}
+	steer(ghost, ManhattanTo(predTileX, predTileY), lvl, rng)
 }
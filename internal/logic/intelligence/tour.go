@@ -0,0 +1,46 @@
+package intelligence
+
+import (
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// PelletTour returns the number of moves it takes to clear every pellet when
+// always walking to the nearest remaining one from start. It is not the
+// shortest possible tour, which makes it a fair par to beat.
+func PelletTour(lvl *model.Level, start types.Tile) int {
+	remaining := make(map[types.Tile]bool)
+	for y := 0; y < lvl.Height; y++ {
+		for x := 0; x < lvl.Width; x++ {
+			if lvl.GetTile(x, y) == model.TilePel {
+				remaining[types.Tile{X: x, Y: y}] = true
+			}
+		}
+	}
+	delete(remaining, start)
+
+	dm := NewDistanceMap(lvl.Width, lvl.Height)
+	moves := 0
+	current := start
+	for len(remaining) > 0 {
+		dm.BuildBFS(physics.TileCenter(current.X, current.Y), lvl)
+
+		nearest, best := types.Tile{}, 1<<30
+		for tile := range remaining {
+			d := dm.GetDistance(tile.X, tile.Y)
+			if d < best || (d == best && (tile.Y < nearest.Y || tile.Y == nearest.Y && tile.X < nearest.X)) {
+				nearest, best = tile, d
+			}
+		}
+		if best == 1<<30 {
+			// The rest cannot be reached, so they cannot count against the player
+			break
+		}
+
+		moves += best
+		current = nearest
+		delete(remaining, nearest)
+	}
+	return moves
+}
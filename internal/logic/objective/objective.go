@@ -0,0 +1,135 @@
+package objective
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+)
+
+// Progress is what the player has achieved so far in a level
+type Progress struct {
+	Pellets      int
+	TotalPellets int
+	Ghosts       int // ghosts eaten
+	Fruit        int // history items collected
+	FruitMissed  int // history items that vanished uncollected and will not come back
+	TotalFruit   int // history items scheduled for the level
+	Seconds      float64
+	ReachedExit  bool
+}
+
+// Status is how an objective stands
+type Status struct {
+	Objective config.Objective
+	Met       bool   // done, or for a time limit, still within it
+	Failed    bool   // can no longer be met
+	Text      string // description with progress for the HUD
+}
+
+// Evaluate returns the status of each objective given the progress so far
+func Evaluate(objectives []config.Objective, p Progress) []Status {
+	statuses := make([]Status, len(objectives))
+	for i, obj := range objectives {
+		s := Status{Objective: obj}
+
+		switch obj.Kind {
+		case "pellets":
+			target := obj.Count
+			if target == 0 {
+				target = p.TotalPellets
+			}
+			s.Met = p.Pellets >= target
+			s.Text = fmt.Sprintf("Pellets %d/%d", min(p.Pellets, target), target)
+		case "survive":
+			s.Met = p.Seconds >= obj.Time
+			s.Text = fmt.Sprintf("Survive %d/%ds", seconds(min(p.Seconds, obj.Time)), seconds(obj.Time))
+		case "fruit":
+			s.Met = p.Fruit >= p.TotalFruit
+			s.Failed = !s.Met && p.FruitMissed > 0
+			s.Text = fmt.Sprintf("Fruit %d/%d", p.Fruit, p.TotalFruit)
+		case "exit":
+			s.Met = p.ReachedExit
+			s.Text = "Reach the exit"
+		case "ghosts":
+			s.Met = p.Ghosts >= obj.Count
+			s.Text = fmt.Sprintf("Ghosts %d/%d", min(p.Ghosts, obj.Count), obj.Count)
+		case "time_limit":
+			s.Met = p.Seconds <= obj.Time
+			s.Failed = !s.Met
+			s.Text = fmt.Sprintf("Time %d/%ds", seconds(p.Seconds), seconds(obj.Time))
+		}
+
+		statuses[i] = s
+	}
+	return statuses
+}
+
+// Won reports whether the required objectives of a level are met. Time limits
+// never win a level by themselves, but a failed one stops it being won.
+func Won(level config.LevelRules, statuses []Status) bool {
+	metAny, metAll := false, true
+	for _, s := range statuses {
+		if s.Objective.Optional {
+			continue
+		}
+		if s.Objective.Kind == "time_limit" {
+			if s.Failed {
+				return false
+			}
+			continue
+		}
+		metAny = metAny || s.Met
+		metAll = metAll && s.Met
+	}
+
+	if level.Require == "any" {
+		return metAny
+	}
+	return metAll
+}
+
+// Lost reports whether the level can no longer be won: a required time limit
+// ran out, or the required objectives it takes to win have failed, all of
+// them when any one will do
+func Lost(level config.LevelRules, statuses []Status) bool {
+	failedAny, failedAll := false, true
+	for _, s := range statuses {
+		if s.Objective.Optional {
+			continue
+		}
+		if s.Objective.Kind == "time_limit" {
+			if s.Failed {
+				return true
+			}
+			continue
+		}
+		failedAny = failedAny || s.Failed
+		failedAll = failedAll && s.Failed
+	}
+
+	if level.Require == "any" {
+		return failedAll
+	}
+	return failedAny
+}
+
+// Stars rates a won level: one star for winning and one for each optional
+// objective met
+func Stars(statuses []Status) (earned, total int) {
+	earned, total = 1, 1
+	for _, s := range statuses {
+		if s.Objective.Optional {
+			total++
+			if s.Met {
+				earned++
+			}
+		}
+	}
+	return earned, total
+}
+
+// seconds rounds a time up to whole seconds for display
+func seconds(t float64) int {
+	return int(math.Ceil(t))
+}
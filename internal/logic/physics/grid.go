@@ -0,0 +1,23 @@
+package physics
+
+import (
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// StepTile moves an entity from its tile center exactly one tile in dir, for
+// turn-based play. It returns false and leaves the entity in place when a
+// wall is in the way.
+func StepTile(entity *model.Entity, dir types.Vector, lvl *model.Level) bool {
+	entity.PrevPos = entity.Pos
+
+	if dir.Eq(types.Vector{}) || !CanTurn(entity, dir, lvl) {
+		return false
+	}
+
+	tileX, tileY := PosToTile(entity.Pos)
+	entity.Pos = TileCenter(tileX+int(dir.X), tileY+int(dir.Y))
+	entity.Dir = dir
+	entity.WantDir = dir
+	return true
+}
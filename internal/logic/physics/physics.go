@@ -1,55 +1,89 @@
 package physics
 
 import (
-	"math"
-
+	"github.com/vladyslavpavlenko/pacman/internal/config"
 	"github.com/vladyslavpavlenko/pacman/internal/model"
 	"github.com/vladyslavpavlenko/pacman/internal/types"
 )
 
-const (
-	TileSize = 24
-)
+const TileSize = 24
+
+// SpeedPerTick converts a speed in tiles per second to sub-pixels per tick
+func SpeedPerTick(tilesPerSecond float64, tickRate int) types.Fixed {
+	return types.FixedFromFloat(tilesPerSecond * TileSize / float64(tickRate))
+}
+
+// AccelPerTick converts an acceleration in tiles per second squared to
+// sub-pixels per tick gained each tick
+func AccelPerTick(tilesPerSecondSq float64, tickRate int) types.Fixed {
+	return types.FixedFromFloat(tilesPerSecondSq * TileSize / float64(tickRate*tickRate))
+}
 
 // TileCenter returns the pixel center coordinates of a tile
-func TileCenter(tileX, tileY int) types.Vector {
-	return types.Vector{
-		X: float64(tileX*TileSize + TileSize/2),
-		Y: float64(tileY*TileSize + TileSize/2),
+func TileCenter(tileX, tileY int) types.Point {
+	return types.Point{
+		X: types.FixedFromInt(tileX*TileSize + TileSize/2),
+		Y: types.FixedFromInt(tileY*TileSize + TileSize/2),
 	}
 }
 
 // PosToTile converts pixel coordinates to tile coordinates
-func PosToTile(pos types.Vector) (tileX, tileY int) {
-	return int(pos.X) / TileSize, int(pos.Y) / TileSize
+func PosToTile(pos types.Point) (tileX, tileY int) {
+	return pos.X.Int() / TileSize, pos.Y.Int() / TileSize
 }
 
-// NearCenter checks if a position is near the center of its tile (for player turning)
-func NearCenter(pos types.Vector) bool {
+// withinCenter checks if a position is within the given number of pixels of its tile center
+func withinCenter(pos types.Point, pixels types.Fixed) bool {
 	tileX, tileY := PosToTile(pos)
-	center := TileCenter(tileX, tileY)
-	return math.Abs(pos.X-center.X) <= 4.0 && math.Abs(pos.Y-center.Y) <= 4.0
+	offset := pos.Sub(TileCenter(tileX, tileY))
+	return offset.X.Abs() <= pixels && offset.Y.Abs() <= pixels
+}
+
+// NearCenter checks if a position is near the center of its tile (for player turning)
+func NearCenter(pos types.Point) bool {
+	return withinCenter(pos, 4*types.FixedOne)
 }
 
 // AtCenter checks if a position is at the center of its tile (for AI decisions)
-func AtCenter(pos types.Vector) bool {
-	tileX, tileY := PosToTile(pos)
-	center := TileCenter(tileX, tileY)
-	return math.Abs(pos.X-center.X) <= 1.0 && math.Abs(pos.Y-center.Y) <= 1.0
+func AtCenter(pos types.Point) bool {
+	return withinCenter(pos, types.FixedOne)
 }
 
 // VeryNearCenter checks if a position is very close to the center (for immediate turns)
-func VeryNearCenter(pos types.Vector) bool {
-	tileX, tileY := PosToTile(pos)
-	center := TileCenter(tileX, tileY)
-	return math.Abs(pos.X-center.X) <= 2.0 && math.Abs(pos.Y-center.Y) <= 2.0
+func VeryNearCenter(pos types.Point) bool {
+	return withinCenter(pos, 2*types.FixedOne)
 }
 
 // CanTurn checks if an entity can turn in the desired direction
-func CanTurn(pos types.Vector, wantDir types.Vector, lvl *model.Level) bool {
-	tileX, tileY := PosToTile(pos)
-	nextX, nextY := tileX+int(wantDir.X), tileY+int(wantDir.Y)
-	return lvl.CanWalk(nextX, nextY)
+func CanTurn(entity *model.Entity, wantDir types.Vector, lvl *model.Level) bool {
+	tileX, tileY := PosToTile(entity.Pos)
+	return canEnter(entity, tileX+int(wantDir.X), tileY+int(wantDir.Y), lvl)
+}
+
+// canEnter checks if an entity may occupy a tile. Phasing entities pass
+// through walls, except for the outer wall of the maze.
+func canEnter(entity *model.Entity, tileX, tileY int, lvl *model.Level) bool {
+	if entity.Phasing {
+		return tileX > 0 && tileY > 0 && tileX < lvl.Width-1 && tileY < lvl.Height-1
+	}
+	return lvl.CanWalk(tileX, tileY)
+}
+
+// CanCorner checks if an entity may start a perpendicular turn early or late,
+// within its cornering window around the tile center
+func CanCorner(entity *model.Entity, lvl *model.Level) bool {
+	if entity.Cornering <= 0 || entity.Dir.Eq(types.Vector{}) {
+		return false
+	}
+
+	// Only perpendicular turns can cut a corner
+	if entity.Dir.X*entity.WantDir.X+entity.Dir.Y*entity.WantDir.Y != 0 {
+		return false
+	}
+
+	tileX, tileY := PosToTile(entity.Pos)
+	offset := entity.Pos.Sub(TileCenter(tileX, tileY))
+	return offset.X.Abs()+offset.Y.Abs() <= entity.Cornering && CanTurn(entity, entity.WantDir, lvl)
 }
 
 // TryTurn attempts to turn an entity in the desired direction
@@ -64,7 +98,12 @@ func TryTurn(entity *model.Entity, wantDir types.Vector, lvl *model.Level) {
 		return
 	}
 
-	if !CanTurn(entity.Pos, wantDir, lvl) {
+	if CanCorner(entity, lvl) {
+		entity.Dir = wantDir
+		return
+	}
+
+	if !CanTurn(entity, wantDir, lvl) {
 		return
 	}
 
@@ -77,12 +116,19 @@ func TryTurn(entity *model.Entity, wantDir types.Vector, lvl *model.Level) {
 
 // StepMove moves an entity one step in its current direction
 func StepMove(entity *model.Entity, lvl *model.Level) {
-	if !entity.WantDir.Eq(entity.Dir) && CanTurn(entity.Pos, entity.WantDir, lvl) {
-		// If we're at center (for AI) or near center (for player), turn
-		if AtCenter(entity.Pos) || NearCenter(entity.Pos) {
+	entity.PrevPos = entity.Pos
+
+	if !entity.WantDir.Eq(entity.Dir) {
+		if CanCorner(entity, lvl) {
+			// Keep the offset; the move below cuts the corner diagonally
 			entity.Dir = entity.WantDir
-			tileX, tileY := PosToTile(entity.Pos)
-			entity.Pos = TileCenter(tileX, tileY)
+		} else if CanTurn(entity, entity.WantDir, lvl) {
+			// If we're at center (for AI) or near center (for player), turn
+			if AtCenter(entity.Pos) || NearCenter(entity.Pos) {
+				entity.Dir = entity.WantDir
+				tileX, tileY := PosToTile(entity.Pos)
+				entity.Pos = TileCenter(tileX, tileY)
+			}
 		}
 	}
 
@@ -90,40 +136,86 @@ func StepMove(entity *model.Entity, lvl *model.Level) {
 		return
 	}
 
-	next := entity.Pos.Add(entity.Dir.Mul(entity.Speed))
+	speed := entity.Speed
+	if entity.Momentum != nil {
+		speed = stepMomentum(entity.Momentum, entity.Dir, entity.Speed)
+	}
 
-	// Check collision with proper hitbox
-	if !CanMoveTo(next, lvl) {
-		currentTileX, currentTileY := PosToTile(entity.Pos)
-		center := TileCenter(currentTileX, currentTileY)
+	currentTileX, currentTileY := PosToTile(entity.Pos)
+	center := TileCenter(currentTileX, currentTileY)
 
-		if entity.Dir.X != 0 {
-			entity.Pos.X = center.X
-		}
-		if entity.Dir.Y != 0 {
-			entity.Pos.Y = center.Y
-		}
+	next := entity.Pos.Move(entity.Dir, speed)
+
+	// Drift back onto the lane while moving, which is what makes a cut
+	// corner diagonal. Entities already on the lane are unaffected.
+	lane := next
+	if entity.Dir.X != 0 {
+		next.Y = approach(entity.Pos.Y, center.Y, speed)
+		lane.Y = center.Y
+	} else {
+		next.X = approach(entity.Pos.X, center.X, speed)
+		lane.X = center.X
+	}
+
+	// Check the body against walls as if already centered in the lane
+	if !CanMoveTo(entity, lane, lvl) {
+		entity.Pos = center
 		entity.Dir = types.Vector{}
+		if entity.Momentum != nil {
+			entity.Momentum.Velocity = 0
+		}
 		return
 	}
 
 	entity.Pos = next
 }
 
-// CanMoveTo checks if an entity can move to a position considering hitbox
-func CanMoveTo(pos types.Vector, lvl *model.Level) bool {
-	hitboxSize := float64(TileSize) * 0.8 // 80% of tile size
+// stepMomentum updates a momentum entity's velocity for one tick moving in dir
+// and returns the distance to move. Turning sheds speed, reversing stops dead,
+// and the velocity never exceeds topSpeed, so lowering it slows the entity at once.
+func stepMomentum(m *model.Momentum, dir types.Vector, topSpeed types.Fixed) types.Fixed {
+	if !dir.Eq(m.Heading) && !m.Heading.Eq(types.Vector{}) {
+		if dir.Eq(m.Heading.Mul(-1)) {
+			m.Velocity = 0
+		} else {
+			m.Velocity = types.Fixed(int64(m.Velocity) * int64(m.TurnKeep) / int64(types.FixedOne))
+		}
+	}
+	m.Heading = dir
+
+	if m.Coasting {
+		m.Velocity = max(m.Velocity-m.Friction, 0)
+	} else {
+		m.Velocity += m.Accel
+	}
+	m.Velocity = min(m.Velocity, topSpeed)
+	return m.Velocity
+}
+
+// approach moves from towards to by at most step without overshooting
+func approach(from, to, step types.Fixed) types.Fixed {
+	if (to - from).Abs() <= step {
+		return to
+	}
+	if to > from {
+		return from + step
+	}
+	return from - step
+}
 
-	corners := []types.Vector{
-		{X: pos.X - hitboxSize/2, Y: pos.Y - hitboxSize/2}, // top-left
-		{X: pos.X + hitboxSize/2, Y: pos.Y - hitboxSize/2}, // top-right
-		{X: pos.X - hitboxSize/2, Y: pos.Y + hitboxSize/2}, // bottom-left
-		{X: pos.X + hitboxSize/2, Y: pos.Y + hitboxSize/2}, // bottom-right
+// CanMoveTo checks if an entity's body fits at a position without touching a wall
+func CanMoveTo(entity *model.Entity, pos types.Point, lvl *model.Level) bool {
+	halfWidth := entity.Collider.Body
+	corners := []types.Point{
+		{X: pos.X - halfWidth, Y: pos.Y - halfWidth}, // top-left
+		{X: pos.X + halfWidth, Y: pos.Y - halfWidth}, // top-right
+		{X: pos.X - halfWidth, Y: pos.Y + halfWidth}, // bottom-left
+		{X: pos.X + halfWidth, Y: pos.Y + halfWidth}, // bottom-right
 	}
 
 	for _, corner := range corners {
 		tileX, tileY := PosToTile(corner)
-		if !lvl.CanWalk(tileX, tileY) {
+		if !canEnter(entity, tileX, tileY, lvl) {
 			return false
 		}
 	}
@@ -134,11 +226,141 @@ func CanMoveTo(pos types.Vector, lvl *model.Level) bool {
 // ResetEntityPosition resets an entity to its spawn position
 func ResetEntityPosition(entity *model.Entity) {
 	entity.Pos = TileCenter(entity.SpawnTile.X, entity.SpawnTile.Y)
+	entity.PrevPos = entity.Pos
 	entity.Dir = types.Vector{}
 	entity.WantDir = types.Vector{}
+	if entity.Momentum != nil {
+		entity.Momentum.Velocity = 0
+		entity.Momentum.Heading = types.Vector{}
+	}
+}
+
+// ResetGhost resets a ghost to its spawn position and forgets the tile it last
+// decided on, so it decides again even when it respawns on that tile
+func ResetGhost(ghost *model.Ghost) {
+	ResetEntityPosition(&ghost.Entity)
+	ghost.DecisionTile = types.Tile{X: -1, Y: -1}
 }
 
 // CheckCollision checks if two entities are colliding within the given radius
-func CheckCollision(entity1, entity2 *model.Entity, radius float64) bool {
-	return entity1.Pos.Add(entity2.Pos.Mul(-1)).Len() <= radius
+func CheckCollision(entity1, entity2 *model.Entity, radius types.Fixed) bool {
+	return entity1.Pos.Sub(entity2.Pos).LenSq() <= int64(radius)*int64(radius)
+}
+
+// contactMode picks how two colliders are tested: sharing a tile wins over
+// shapes, and a box against a circle treats the circle as its bounding box
+func contactMode(c1, c2 model.Collider) config.CollisionMode {
+	switch {
+	case c1.Mode == config.CollideTile || c2.Mode == config.CollideTile:
+		return config.CollideTile
+	case c1.Mode == config.CollideCircle && c2.Mode == config.CollideCircle:
+		return config.CollideCircle
+	default:
+		return config.CollideBox
+	}
+}
+
+// CheckContact checks if the colliders of two entities touch at their current positions
+func CheckContact(entity1, entity2 *model.Entity) bool {
+	reach := entity1.Collider.Size + entity2.Collider.Size
+	offset := entity1.Pos.Sub(entity2.Pos)
+
+	switch contactMode(entity1.Collider, entity2.Collider) {
+	case config.CollideTile:
+		tile1X, tile1Y := PosToTile(entity1.Pos)
+		tile2X, tile2Y := PosToTile(entity2.Pos)
+		return tile1X == tile2X && tile1Y == tile2Y
+	case config.CollideCircle:
+		return offset.LenSq() <= int64(reach)*int64(reach)
+	default:
+		return offset.X.Abs() <= reach && offset.Y.Abs() <= reach
+	}
+}
+
+// CheckSweptCollision checks if the colliders of two entities touched at any
+// point during their last step, not just at its end. Both entities are
+// assumed to move in a straight line from PrevPos to Pos, so fast entities
+// running into each other head-on cannot pass through one another. Swapping
+// tiles in one step also counts as a collision, as in the arcade.
+func CheckSweptCollision(entity1, entity2 *model.Entity) bool {
+	if CheckContact(entity1, entity2) || swappedTiles(entity1, entity2) {
+		return true
+	}
+
+	// Offset between the entities as a function of t in [0, 1]
+	start := entity1.PrevPos.Sub(entity2.PrevPos)
+	end := entity1.Pos.Sub(entity2.Pos)
+	motion := end.Sub(start)
+	reach := entity1.Collider.Size + entity2.Collider.Size
+
+	switch contactMode(entity1.Collider, entity2.Collider) {
+	case config.CollideCircle:
+		return circleSweep(start, motion, reach)
+	case config.CollideBox:
+		return boxSweep(start, motion, reach)
+	default:
+		return false
+	}
+}
+
+// circleSweep reports whether the offset start+motion*t comes within radius
+// of the origin for some t in [0, 1]
+func circleSweep(start, motion types.Point, radius types.Fixed) bool {
+	radiusSq := int64(radius) * int64(radius)
+	if start.LenSq() <= radiusSq || start.Add(motion).LenSq() <= radiusSq {
+		return true
+	}
+
+	// The closest approach lies strictly inside the step when the offset
+	// shrinks at t=0 and grows again by t=1. Its squared distance is
+	// |start|^2 - (start.motion)^2/|motion|^2, compared without dividing.
+	along := start.Dot(motion)
+	motionSq := motion.LenSq()
+	if along < 0 && -along < motionSq {
+		return start.LenSq()*motionSq-along*along <= radiusSq*motionSq
+	}
+	return false
+}
+
+// boxSweep reports whether the offset start+motion*t lies within halfWidth of
+// the origin on both axes for some t in [0, 1]
+func boxSweep(start, motion types.Point, halfWidth types.Fixed) bool {
+	// Times kept as fractions num/den, starting with the whole step
+	loNum, loDen := int64(0), int64(1)
+	hiNum, hiDen := int64(1), int64(1)
+	h := int64(halfWidth)
+
+	for _, axis := range [][2]types.Fixed{{start.X, motion.X}, {start.Y, motion.Y}} {
+		s, m := int64(axis[0]), int64(axis[1])
+		if m == 0 {
+			if s < -h || s > h {
+				return false
+			}
+			continue
+		}
+
+		// Times at which this axis enters and leaves the band [-h, h]
+		enter, exit, den := -h-s, h-s, m
+		if m < 0 {
+			enter, exit, den = s-h, s+h, -m
+		}
+		if enter*loDen > loNum*den {
+			loNum, loDen = enter, den
+		}
+		if exit*hiDen < hiNum*den {
+			hiNum, hiDen = exit, den
+		}
+	}
+
+	return loNum*hiDen <= hiNum*loDen
+}
+
+// swappedTiles reports whether two entities exchanged tiles during their last step
+func swappedTiles(entity1, entity2 *model.Entity) bool {
+	prev1X, prev1Y := PosToTile(entity1.PrevPos)
+	prev2X, prev2Y := PosToTile(entity2.PrevPos)
+	cur1X, cur1Y := PosToTile(entity1.Pos)
+	cur2X, cur2Y := PosToTile(entity2.Pos)
+	swapped := prev1X == cur2X && prev1Y == cur2Y && prev2X == cur1X && prev2Y == cur1Y
+	return swapped && (cur1X != cur2X || cur1Y != cur2Y)
 }
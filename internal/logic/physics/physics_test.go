@@ -0,0 +1,281 @@
+package physics
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// junction is a plus-shaped maze: a corridor along row 2 crossed by one along
+// column 2, so tile (2, 2) is the only place to turn
+var junction = []string{
+	"#####",
+	"##.##",
+	"#...#",
+	"##.##",
+	"#####",
+}
+
+var (
+	up    = types.Vector{X: 0, Y: -1}
+	left  = types.Vector{X: -1, Y: 0}
+	right = types.Vector{X: 1, Y: 0}
+)
+
+// px converts whole pixels to fixed point
+func px(pixels int) types.Fixed {
+	return types.FixedFromInt(pixels)
+}
+
+// newEntity creates an entity with the default body at pos, moving one pixel
+// per tick in dir and wanting to turn to want
+func newEntity(pos types.Point, dir, want types.Vector, cornering types.Fixed) *model.Entity {
+	return &model.Entity{
+		Pos:       pos,
+		PrevPos:   pos,
+		Dir:       dir,
+		WantDir:   want,
+		Speed:     px(1),
+		Cornering: cornering,
+		Collider:  model.NewCollider(config.ColliderConfig{Mode: config.CollideCircle, Size: 4, Body: 9.6}),
+	}
+}
+
+// offsetFrom returns the point dx, dy pixels away from the center of a tile
+func offsetFrom(tileX, tileY, dx, dy int) types.Point {
+	return TileCenter(tileX, tileY).Add(types.Point{X: px(dx), Y: px(dy)})
+}
+
+func TestCanCorner(t *testing.T) {
+	tests := []struct {
+		name      string
+		pos       types.Point
+		dir, want types.Vector
+		cornering types.Fixed
+		expect    bool
+	}{
+		{"pre-turn inside the window", offsetFrom(2, 2, -3, 0), right, up, px(6), true},
+		{"post-turn inside the window", offsetFrom(2, 2, 3, 0), right, up, px(6), true},
+		{"at the edge of the window", offsetFrom(2, 2, -6, 0), right, up, px(6), true},
+		{"outside the window", offsetFrom(2, 2, -7, 0), right, up, px(6), false},
+		{"blocked corner", offsetFrom(1, 2, 2, 0), right, up, px(6), false},
+		{"reversing is not a corner", offsetFrom(2, 2, -3, 0), right, left, px(6), false},
+		{"standing still", offsetFrom(2, 2, 0, 0), types.Vector{}, up, px(6), false},
+		{"no cornering window", offsetFrom(2, 2, -3, 0), right, up, 0, false},
+	}
+
+	lvl := model.New(junction)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEntity(tt.pos, tt.dir, tt.want, tt.cornering)
+			if got := CanCorner(e, lvl); got != tt.expect {
+				t.Errorf("CanCorner = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestStepMoveCornering(t *testing.T) {
+	center := TileCenter(2, 2)
+	tests := []struct {
+		name      string
+		pos       types.Point
+		cornering types.Fixed
+		wantDir   types.Vector
+		wantPos   types.Point
+	}{
+		{
+			// The player keeps its offset and drifts back onto the lane while
+			// already moving up, cutting the corner diagonally
+			name:      "player pre-turn cuts the corner",
+			pos:       offsetFrom(2, 2, -3, 0),
+			cornering: px(6),
+			wantDir:   up,
+			wantPos:   types.Point{X: center.X - px(2), Y: center.Y - px(1)},
+		},
+		{
+			name:      "player post-turn cuts the corner",
+			pos:       offsetFrom(2, 2, 3, 0),
+			cornering: px(6),
+			wantDir:   up,
+			wantPos:   types.Point{X: center.X + px(2), Y: center.Y - px(1)},
+		},
+		{
+			// Ghosts have no cornering window, so they snap to the center and
+			// turn exactly there
+			name:      "ghost turns at the center",
+			pos:       offsetFrom(2, 2, -3, 0),
+			cornering: 0,
+			wantDir:   up,
+			wantPos:   types.Point{X: center.X, Y: center.Y - px(1)},
+		},
+		{
+			name:      "ghost too far from the center keeps going",
+			pos:       offsetFrom(2, 2, -6, 0),
+			cornering: 0,
+			wantDir:   right,
+			wantPos:   offsetFrom(2, 2, -5, 0),
+		},
+		{
+			name:      "player before a blocked corner keeps going",
+			pos:       offsetFrom(1, 2, 2, 0),
+			cornering: px(6),
+			wantDir:   right,
+			wantPos:   offsetFrom(1, 2, 3, 0),
+		},
+	}
+
+	lvl := model.New(junction)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEntity(tt.pos, right, up, tt.cornering)
+			StepMove(e, lvl)
+			if !e.Dir.Eq(tt.wantDir) {
+				t.Errorf("Dir = %v, want %v", e.Dir, tt.wantDir)
+			}
+			if !e.Pos.Eq(tt.wantPos) {
+				t.Errorf("Pos = %v, want %v", e.Pos, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestStepMoveCutIsDiagonal(t *testing.T) {
+	lvl := model.New(junction)
+	e := newEntity(offsetFrom(2, 2, -3, 0), right, up, px(6))
+
+	// Every step of the cut moves on both axes until the player is back on
+	// the vertical lane
+	center := TileCenter(2, 2)
+	for e.Pos.X != center.X {
+		StepMove(e, lvl)
+		step := e.Pos.Sub(e.PrevPos)
+		if step.X == 0 || step.Y == 0 {
+			t.Fatalf("step %v from %v is not diagonal", step, e.PrevPos)
+		}
+	}
+	if !e.Dir.Eq(up) {
+		t.Errorf("Dir = %v, want up", e.Dir)
+	}
+}
+
+// movingEntity creates an entity with the given collider that moved from
+// prev to pos in its last step
+func movingEntity(mode config.CollisionMode, prev, pos types.Point) *model.Entity {
+	return &model.Entity{
+		PrevPos:  prev,
+		Pos:      pos,
+		Collider: model.NewCollider(config.ColliderConfig{Mode: mode, Size: 4, Body: 9.6}),
+	}
+}
+
+// at returns a point in whole pixels
+func at(x, y int) types.Point {
+	return types.Point{X: px(x), Y: px(y)}
+}
+
+func TestCheckSweptCollision(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         config.CollisionMode
+		prev1, pos1  types.Point
+		prev2, pos2  types.Point
+		wantContact  bool
+		wantCollided bool
+	}{
+		{
+			// 20 px per tick each way: the colliders (8 px reach) overlap
+			// mid-step but are 22 px apart again at its end
+			name:  "circles tunnel head-on",
+			mode:  config.CollideCircle,
+			prev1: at(100, 60), pos1: at(120, 60),
+			prev2: at(118, 60), pos2: at(98, 60),
+			wantContact: false, wantCollided: true,
+		},
+		{
+			name:  "boxes tunnel head-on",
+			mode:  config.CollideBox,
+			prev1: at(100, 60), pos1: at(120, 60),
+			prev2: at(118, 60), pos2: at(98, 60),
+			wantContact: false, wantCollided: true,
+		},
+		{
+			name:  "circles tunnel at just over 8 px per tick",
+			mode:  config.CollideCircle,
+			prev1: at(100, 60), pos1: at(109, 60),
+			prev2: at(109, 60), pos2: at(100, 60),
+			wantContact: false, wantCollided: true,
+		},
+		{
+			// Tile mode has no shape to sweep; exchanging tiles is the catch
+			name:  "tiles swapped in one step",
+			mode:  config.CollideTile,
+			prev1: TileCenter(4, 2), pos1: TileCenter(5, 2),
+			prev2: TileCenter(5, 2), pos2: TileCenter(4, 2),
+			wantContact: false, wantCollided: true,
+		},
+		{
+			name:  "circles passing in neighbouring lanes",
+			mode:  config.CollideCircle,
+			prev1: at(100, 60), pos1: at(120, 60),
+			prev2: at(118, 69), pos2: at(98, 69),
+			wantContact: false, wantCollided: false,
+		},
+		{
+			name:  "boxes passing in neighbouring lanes",
+			mode:  config.CollideBox,
+			prev1: at(100, 60), pos1: at(120, 60),
+			prev2: at(118, 69), pos2: at(98, 69),
+			wantContact: false, wantCollided: false,
+		},
+		{
+			name:  "circles stopping just short of each other",
+			mode:  config.CollideCircle,
+			prev1: at(80, 60), pos1: at(100, 60),
+			prev2: at(129, 60), pos2: at(109, 60),
+			wantContact: false, wantCollided: false,
+		},
+		{
+			name:  "boxes crossing paths without meeting",
+			mode:  config.CollideBox,
+			prev1: at(100, 100), pos1: at(120, 100),
+			prev2: at(130, 80), pos2: at(130, 120),
+			wantContact: false, wantCollided: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e1 := movingEntity(tt.mode, tt.prev1, tt.pos1)
+			e2 := movingEntity(tt.mode, tt.prev2, tt.pos2)
+			if got := CheckContact(e1, e2); got != tt.wantContact {
+				t.Errorf("CheckContact = %v, want %v", got, tt.wantContact)
+			}
+			if got := CheckSweptCollision(e1, e2); got != tt.wantCollided {
+				t.Errorf("CheckSweptCollision = %v, want %v", got, tt.wantCollided)
+			}
+			// The result must not depend on the order of the entities
+			if got := CheckSweptCollision(e2, e1); got != tt.wantCollided {
+				t.Errorf("CheckSweptCollision reversed = %v, want %v", got, tt.wantCollided)
+			}
+		})
+	}
+}
+
+func TestResetGhostForgetsDecision(t *testing.T) {
+	ghost := model.NewGhost(2, 2, px(1), color.RGBA{}, config.GhostSkillLevelNormal)
+	ghost.Pos = offsetFrom(3, 2, 4, 0)
+	ghost.Dir = right
+	ghost.DecisionTile = types.Tile{X: 2, Y: 2}
+
+	ResetGhost(ghost)
+	if !ghost.Pos.Eq(TileCenter(2, 2)) {
+		t.Errorf("Pos = %v, want the center of the spawn tile", ghost.Pos)
+	}
+	if ghost.DecisionTile != (types.Tile{X: -1, Y: -1}) {
+		t.Errorf("DecisionTile = %v, want none", ghost.DecisionTile)
+	}
+}
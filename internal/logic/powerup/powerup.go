@@ -0,0 +1,156 @@
+package powerup
+
+import (
+	"fmt"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/clock"
+)
+
+// Names of the built-in effects
+const (
+	Speed        = "speed"        // raises the player's speed
+	Freeze       = "freeze"       // stops the ghosts where they are
+	Invisibility = "invisibility" // ghosts lose track of the player and wander
+	Magnet       = "magnet"       // pulls in pellets around the player
+	Phasing      = "phasing"      // lets the player pass through inner walls
+	Shield       = "shield"       // lets the player eat any ghost that catches them
+)
+
+// Type describes a kind of effect
+type Type struct {
+	Name  string
+	Label string // shown next to the effect's timer in the HUD
+}
+
+// registry holds every effect type a pickup can grant, keyed by name
+var registry = map[string]Type{}
+
+// Register adds an effect type, replacing any type of the same name, and
+// lets the rules tune it and grant it from pickups
+func Register(t Type) {
+	registry[t.Name] = t
+	config.RegisterPowerUp(t.Name)
+}
+
+// Lookup returns the effect type with the given name
+func Lookup(name string) (Type, bool) {
+	t, ok := registry[name]
+	return t, ok
+}
+
+func init() {
+	Register(Type{Name: Speed, Label: "SPEED"})
+	Register(Type{Name: Freeze, Label: "FREEZE"})
+	Register(Type{Name: Invisibility, Label: "INVISIBLE"})
+	Register(Type{Name: Magnet, Label: "MAGNET"})
+	Register(Type{Name: Phasing, Label: "PHASING"})
+	Register(Type{Name: Shield, Label: "SHIELD"})
+}
+
+// Active is an effect currently running
+type Active struct {
+	Type      Type
+	Strength  float64
+	TicksLeft int
+}
+
+// Manager runs the player's timed effects. Effects are kept in the order they
+// were first granted, which is the order the HUD lists them in.
+type Manager struct {
+	rules  map[string]config.PowerUpConfig
+	clock  *clock.Clock
+	active []Active
+}
+
+// New creates a manager with no active effects
+func New(rules map[string]config.PowerUpConfig, clk *clock.Clock) *Manager {
+	return &Manager{rules: rules, clock: clk}
+}
+
+// Grant starts the named effect. Granting an effect that is already active
+// refreshes or extends it according to its stacking mode.
+func (m *Manager) Grant(name string) error {
+	t, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("power-up %q is not registered", name)
+	}
+	rule, ok := m.rules[name]
+	if !ok {
+		return fmt.Errorf("power-up %q has no rules", name)
+	}
+
+	ticks := max(m.clock.Ticks(rule.Time), 1)
+	for i := range m.active {
+		a := &m.active[i]
+		if a.Type.Name != name {
+			continue
+		}
+		if rule.Stacking == "stack" {
+			a.TicksLeft += ticks
+		} else {
+			a.TicksLeft = max(a.TicksLeft, ticks)
+		}
+		return nil
+	}
+
+	m.active = append(m.active, Active{Type: t, Strength: rule.Strength, TicksLeft: ticks})
+	return nil
+}
+
+// Update runs the timers down by the given number of ticks and drops the
+// effects that ran out
+func (m *Manager) Update(ticks int) {
+	kept := m.active[:0]
+	for _, a := range m.active {
+		a.TicksLeft -= ticks
+		if a.TicksLeft > 0 {
+			kept = append(kept, a)
+		}
+	}
+	m.active = kept
+}
+
+// Has reports whether the named effect is active
+func (m *Manager) Has(name string) bool {
+	_, ok := m.find(name)
+	return ok
+}
+
+// Strength returns the strength of the named effect, or 0 when it is not active
+func (m *Manager) Strength(name string) float64 {
+	if i, ok := m.find(name); ok {
+		return m.active[i].Strength
+	}
+	return 0
+}
+
+// Active returns the running effects in the order they were granted
+func (m *Manager) Active() []Active {
+	return m.active
+}
+
+// Clear ends every effect
+func (m *Manager) Clear() {
+	m.active = nil
+}
+
+// Snapshot returns a copy of the running effects that Restore can bring back
+func (m *Manager) Snapshot() []Active {
+	return append([]Active(nil), m.active...)
+}
+
+// Restore replaces the running effects with a snapshot
+func (m *Manager) Restore(snapshot []Active) {
+	m.active = append([]Active(nil), snapshot...)
+}
+
+// find returns the index of the named effect among the active ones
+func (m *Manager) find(name string) (int, bool) {
+	for i, a := range m.active {
+		if a.Type.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package scoring
+
+import "github.com/vladyslavpavlenko/pacman/internal/config"
+
+// Award is the outcome of a single scoring event
+type Award struct {
+	Points     int
+	ExtraLives int // extra lives earned by crossing thresholds with these points
+}
+
+// Scorer keeps the score and turns game events into points according to the
+// scoring rules. Every point in the game goes through it, so anything that
+// reports points sees the same numbers. A Scorer is a plain value and can be
+// copied to save and restore its state.
+type Scorer struct {
+	rules      config.ScoringRules
+	score      int
+	chain      int // ghosts eaten during the current power-up
+	livesGiven int // extra lives awarded so far
+}
+
+// New creates a scorer starting from zero
+func New(rules config.ScoringRules) *Scorer {
+	return &Scorer{rules: rules}
+}
+
+// Score returns the current score
+func (s *Scorer) Score() int {
+	return s.score
+}
+
+// Pellet scores an eaten pellet
+func (s *Scorer) Pellet() Award {
+	return s.add(s.rules.Pellet)
+}
+
+// PowerPellet scores an eaten power pellet, or anything else that lets the
+// player eat ghosts, and starts a new ghost chain
+func (s *Scorer) PowerPellet() Award {
+	s.chain = 0
+	return s.add(s.rules.PowerPellet)
+}
+
+// Ghost scores an eaten ghost. Each ghost in the same chain is worth the next
+// value of the chain, and the last value repeats once the chain runs out.
+func (s *Scorer) Ghost() Award {
+	i := min(s.chain, len(s.rules.GhostChain)-1)
+	s.chain++
+	return s.add(s.rules.GhostChain[i])
+}
+
+// EndChain ends the current ghost chain, for example when a power-up wears off
+// or is used up
+func (s *Scorer) EndChain() {
+	s.chain = 0
+}
+
+// Item scores a collected item worth the given points
+func (s *Scorer) Item(points int) Award {
+	return s.add(points)
+}
+
+// Second scores a second survived
+func (s *Scorer) Second() Award {
+	return s.add(s.rules.Second)
+}
+
+// add adds points to the score and reports any extra lives they earned
+func (s *Scorer) add(points int) Award {
+	s.score += points
+
+	award := Award{Points: points}
+	for {
+		threshold, ok := s.threshold(s.livesGiven)
+		if !ok || s.score < threshold {
+			break
+		}
+		s.livesGiven++
+		award.ExtraLives++
+	}
+	return award
+}
+
+// threshold returns the score at which the n-th extra life is awarded
+func (s *Scorer) threshold(n int) (int, bool) {
+	if n < len(s.rules.ExtraLifeAt) {
+		return s.rules.ExtraLifeAt[n], true
+	}
+	if s.rules.ExtraLifeEvery <= 0 {
+		return 0, false
+	}
+
+	base := 0
+	if len(s.rules.ExtraLifeAt) > 0 {
+		base = s.rules.ExtraLifeAt[len(s.rules.ExtraLifeAt)-1]
+	}
+	return base + s.rules.ExtraLifeEvery*(n-len(s.rules.ExtraLifeAt)+1), true
+}
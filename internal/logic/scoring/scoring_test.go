@@ -0,0 +1,58 @@
+package scoring
+
+import (
+	"testing"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/clock"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/powerup"
+)
+
+func TestGhostChainUnderOneShield(t *testing.T) {
+	rules := config.DefaultRules()
+	clk := clock.New(rules.TickRate, 1)
+	powerups := powerup.New(rules.PowerUps, clk)
+	scorer := New(rules.Scoring)
+
+	if err := powerups.Grant(powerup.Shield); err != nil {
+		t.Fatal(err)
+	}
+	scorer.PowerPellet()
+
+	// A ghost every second while the shield lasts, with the chain ending only
+	// once it runs out, the way the game plays it
+	want := []int{200, 400, 800, 1600, 1600}
+	for i, points := range want {
+		if !powerups.Has(powerup.Shield) {
+			t.Fatalf("shield ran out before ghost %d", i+1)
+		}
+		if got := scorer.Ghost().Points; got != points {
+			t.Errorf("ghost %d scored %d, want %d", i+1, got, points)
+		}
+		powerups.Update(clk.Ticks(1))
+		if !powerups.Has(powerup.Shield) {
+			scorer.EndChain()
+		}
+	}
+
+	powerups.Update(clk.Ticks(rules.PowerUps[powerup.Shield].Time))
+	if powerups.Has(powerup.Shield) {
+		t.Fatal("shield still up after its time")
+	}
+	scorer.EndChain()
+	if got := scorer.Ghost().Points; got != 200 {
+		t.Errorf("first ghost after the shield scored %d, want 200", got)
+	}
+}
+
+func TestPowerPelletStartsNewChain(t *testing.T) {
+	scorer := New(config.DefaultRules().Scoring)
+	scorer.PowerPellet()
+	scorer.Ghost()
+	scorer.Ghost()
+
+	scorer.PowerPellet()
+	if got := scorer.Ghost().Points; got != 200 {
+		t.Errorf("first ghost of a new chain scored %d, want 200", got)
+	}
+}
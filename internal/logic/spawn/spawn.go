@@ -0,0 +1,251 @@
+package spawn
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/intelligence"
+	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
+	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// Context is what a strategy may look at when placing an item
+type Context struct {
+	Level       *model.Level
+	Player      types.Tile
+	Ghosts      []types.Tile
+	Blocked     map[types.Tile]bool // tiles no item may appear on, such as spawn points
+	MinDistance int                 // tiles of path the distant strategy keeps from the player and ghosts
+	Rand        *rand.Rand          // source of every random choice, seeded with the level
+}
+
+// Strategy picks a tile for a new item, reporting false when no tile suits it
+type Strategy func(sp *Spawner, ctx Context) (types.Tile, bool)
+
+// strategies holds every placement strategy, keyed by name
+var strategies = map[string]Strategy{}
+
+// Register adds a placement strategy, replacing any strategy of the same
+// name, and lets the rules select it
+func Register(name string, strategy Strategy) {
+	strategies[name] = strategy
+	config.RegisterSpawnStrategy(name)
+}
+
+// The built-in strategies: "center" is the free tile closest to the middle
+// of the maze, "random" any free tile, "distant" a free tile far enough from
+// the player and every ghost, "unvisited" favours tiles the player has been
+// to least, and "fair" alternates between the left and right halves
+func init() {
+	Register("center", centerStrategy)
+	Register("random", randomStrategy)
+	Register("distant", distantStrategy)
+	Register("unvisited", unvisitedStrategy)
+	Register("fair", fairStrategy)
+}
+
+// Spawner places items during a level and keeps what the strategies learn
+// from the player along the way
+type Spawner struct {
+	visits   [][]int // times the player entered each tile
+	lastTile types.Tile
+	halves   [2]int // items placed in the left and right halves of the maze
+}
+
+// NewSpawner creates a spawner for a level of the given size
+func NewSpawner(width, height int) *Spawner {
+	visits := make([][]int, height)
+	for y := range visits {
+		visits[y] = make([]int, width)
+	}
+	return &Spawner{visits: visits, lastTile: types.Tile{X: -1, Y: -1}}
+}
+
+// Visit records the player standing on a tile; only entering a tile counts
+func (sp *Spawner) Visit(tile types.Tile) {
+	if tile == sp.lastTile {
+		return
+	}
+	sp.lastTile = tile
+	if tile.Y >= 0 && tile.Y < len(sp.visits) && tile.X >= 0 && tile.X < len(sp.visits[tile.Y]) {
+		sp.visits[tile.Y][tile.X]++
+	}
+}
+
+// Clone returns an independent copy of the spawner, for undo
+func (sp *Spawner) Clone() *Spawner {
+	clone := *sp
+	clone.visits = make([][]int, len(sp.visits))
+	for y, row := range sp.visits {
+		clone.visits[y] = append([]int(nil), row...)
+	}
+	return &clone
+}
+
+// Place picks a tile for a new item with the named strategy
+func (sp *Spawner) Place(name string, ctx Context) (types.Tile, error) {
+	strategy, ok := strategies[name]
+	if !ok {
+		return types.Tile{}, fmt.Errorf("unknown spawn strategy %q", name)
+	}
+	tile, ok := strategy(sp, ctx)
+	if !ok {
+		return types.Tile{}, fmt.Errorf("spawn strategy %q found no free tile", name)
+	}
+	sp.halves[sp.half(tile, ctx.Level)]++
+	return tile, nil
+}
+
+// half returns 0 for a tile in the left half of the maze and 1 for the right
+func (sp *Spawner) half(tile types.Tile, lvl *model.Level) int {
+	if tile.X < lvl.Width/2 {
+		return 0
+	}
+	return 1
+}
+
+// candidates returns the walkable tiles an item may appear on: not blocked,
+// not holding an item and not under the player or a ghost
+func candidates(ctx Context) []types.Tile {
+	taken := make(map[types.Tile]bool, len(ctx.Blocked)+len(ctx.Ghosts)+1)
+	for tile := range ctx.Blocked {
+		taken[tile] = true
+	}
+	for _, item := range ctx.Level.Items {
+		taken[item.Tile] = true
+	}
+	taken[ctx.Player] = true
+	for _, ghost := range ctx.Ghosts {
+		taken[ghost] = true
+	}
+
+	var tiles []types.Tile
+	for _, tile := range ctx.Level.GetWalkableTiles() {
+		if !taken[tile] {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+// centerStrategy picks the free tile closest to the middle of the maze, the
+// same spot every time like the arcade's fruit position
+func centerStrategy(_ *Spawner, ctx Context) (types.Tile, bool) {
+	centerX, centerY := ctx.Level.Width/2, ctx.Level.Height/2
+
+	best, bestDist := types.Tile{}, -1
+	for _, tile := range candidates(ctx) {
+		dist := abs(tile.X-centerX) + abs(tile.Y-centerY)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = tile, dist
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// randomStrategy picks any free tile
+func randomStrategy(_ *Spawner, ctx Context) (types.Tile, bool) {
+	tiles := candidates(ctx)
+	if len(tiles) == 0 {
+		return types.Tile{}, false
+	}
+	return tiles[ctx.Rand.IntN(len(tiles))], true
+}
+
+// distantStrategy picks a free tile at least MinDistance tiles of path from
+// the player and every ghost. When the maze is too crowded for that, the
+// tile farthest from its nearest chaser is used.
+func distantStrategy(_ *Spawner, ctx Context) (types.Tile, bool) {
+	tiles := candidates(ctx)
+	if len(tiles) == 0 {
+		return types.Tile{}, false
+	}
+
+	// Distance from each tile to the closest of the player and the ghosts
+	nearest := make([]int, len(tiles))
+	for i := range nearest {
+		nearest[i] = 1 << 30
+	}
+	dm := intelligence.NewDistanceMap(ctx.Level.Width, ctx.Level.Height)
+	for _, source := range append([]types.Tile{ctx.Player}, ctx.Ghosts...) {
+		dm.BuildBFS(physics.TileCenter(source.X, source.Y), ctx.Level)
+		for i, tile := range tiles {
+			nearest[i] = min(nearest[i], dm.GetDistance(tile.X, tile.Y))
+		}
+	}
+
+	var far []types.Tile
+	farthest := 0
+	for i, tile := range tiles {
+		if nearest[i] >= ctx.MinDistance {
+			far = append(far, tile)
+		}
+		if nearest[i] > nearest[farthest] {
+			farthest = i
+		}
+	}
+	if len(far) == 0 {
+		return tiles[farthest], true
+	}
+	return far[ctx.Rand.IntN(len(far))], true
+}
+
+// unvisitedStrategy picks a free tile at random, weighting each tile by how
+// rarely the player has entered it, to draw them into parts of the maze they
+// have been avoiding
+func unvisitedStrategy(sp *Spawner, ctx Context) (types.Tile, bool) {
+	tiles := candidates(ctx)
+	if len(tiles) == 0 {
+		return types.Tile{}, false
+	}
+
+	weights := make([]float64, len(tiles))
+	total := 0.0
+	for i, tile := range tiles {
+		weights[i] = 1 / float64(1+sp.visits[tile.Y][tile.X])
+		total += weights[i]
+	}
+
+	r := ctx.Rand.Float64() * total
+	for i, weight := range weights {
+		r -= weight
+		if r < 0 {
+			return tiles[i], true
+		}
+	}
+	return tiles[len(tiles)-1], true
+}
+
+// fairStrategy picks a free tile in whichever half of the maze has had fewer
+// items so far, so neither side is favoured over a level
+func fairStrategy(sp *Spawner, ctx Context) (types.Tile, bool) {
+	tiles := candidates(ctx)
+	if len(tiles) == 0 {
+		return types.Tile{}, false
+	}
+
+	want := 0
+	if sp.halves[1] < sp.halves[0] {
+		want = 1
+	}
+
+	var side []types.Tile
+	for _, tile := range tiles {
+		if sp.half(tile, ctx.Level) == want {
+			side = append(side, tile)
+		}
+	}
+	if len(side) == 0 {
+		side = tiles
+	}
+	return side[ctx.Rand.IntN(len(side))], true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
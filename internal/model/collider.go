@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// Collider describes how an entity collides with walls and other entities
+type Collider struct {
+	Mode config.CollisionMode // how contacts with other entities are decided
+	Size types.Fixed          // radius for circles, half-width for boxes
+	Body types.Fixed          // half-width of the square kept clear of walls
+}
+
+// NewCollider converts a collider configured in pixels to fixed point
+func NewCollider(cfg config.ColliderConfig) Collider {
+	return Collider{
+		Mode: cfg.Mode,
+		Size: types.FixedFromFloat(cfg.Size),
+		Body: types.FixedFromFloat(cfg.Body),
+	}
+}
@@ -8,31 +8,65 @@ import (
 )
 
 type Entity struct {
-	Pos       types.Vector // pixel center position
+	Pos       types.Point  // fixed-point pixel center position
+	PrevPos   types.Point  // fixed-point pixel center position before the last step
 	Dir       types.Vector // normalized grid direction (up/down/left/right or zero)
 	WantDir   types.Vector // desired direction from input/AI
-	Speed     float64      // movement speed in pixels per frame
+	Speed     types.Fixed  // movement speed in sub-pixels per tick
+	Cornering types.Fixed  // distance before or after a tile center a turn may start (0 for exact turns)
+	Collider  Collider     // shape and size used against walls and other entities
+	Momentum  *Momentum    // nil moves at a constant Speed; otherwise Speed is the top speed
+	Phasing   bool         // passes through inner walls, but never leaves the maze
 	Color     color.RGBA   // entity color
 	SpawnTile types.Tile   // spawn tile coordinates
 }
 
+// DrawPos returns the pixel position to draw the entity at, alpha of the way
+// from its previous tick position to its current one
+func (e *Entity) DrawPos(alpha float64) (float64, float64) {
+	x := e.PrevPos.X.Float() + (e.Pos.X-e.PrevPos.X).Float()*alpha
+	y := e.PrevPos.Y.Float() + (e.Pos.Y-e.PrevPos.Y).Float()*alpha
+	return x, y
+}
+
+// Momentum makes an entity build up to its top speed and lose speed in turns
+// instead of always moving at it
+type Momentum struct {
+	Accel    types.Fixed  // speed gained per tick while a direction is held
+	Friction types.Fixed  // speed lost per tick while coasting
+	TurnKeep types.Fixed  // share of speed kept through a turn, FixedOne keeps all of it
+	Velocity types.Fixed  // current speed in sub-pixels per tick
+	Heading  types.Vector // direction the velocity was last applied in
+	Coasting bool         // no direction is held, so friction applies instead of acceleration
+}
+
 type Player struct {
 	Entity
 }
 
 type Ghost struct {
 	Entity
-	SkillLevel config.GhostLevel
+	SkillLevel     config.GhostLevel
+	ReversePending bool       // set on a mode change to make the ghost turn back once
+	DecisionTile   types.Tile // last tile the ghost chose a direction on
 }
 
-type Apple struct {
-	Entity
+// Item is a pickup lying in the maze, such as a bonus fruit. What it is worth
+// and what it does come from its definition, looked up by ID. Only the
+// tagged fields are saved; after loading, Rehydrate rebuilds the rest from
+// the definition.
+type Item struct {
+	Entity    `json:"-"`
+	ID        string     `json:"id"`
+	Sprite    string     `json:"-"`
+	Tile      types.Tile `json:"tile"`
+	TicksLeft int        `json:"ticks_left"` // ticks until the item vanishes; 0 keeps it until collected
 }
 
-func NewPlayer(spawnX, spawnY int, speed float64, color color.RGBA) *Player {
+func NewPlayer(spawnX, spawnY int, speed types.Fixed, color color.RGBA) *Player {
 	return &Player{
 		Entity: Entity{
-			Pos:       types.Vector{},
+			Pos:       types.Point{},
 			Dir:       types.Vector{},
 			WantDir:   types.Vector{},
 			Speed:     speed,
@@ -43,30 +77,38 @@ func NewPlayer(spawnX, spawnY int, speed float64, color color.RGBA) *Player {
 }
 
 // NewGhost creates a new ghost entity
-func NewGhost(spawnX, spawnY int, speed float64, color color.RGBA, skillLevel config.GhostLevel) *Ghost {
+func NewGhost(spawnX, spawnY int, speed types.Fixed, color color.RGBA, skillLevel config.GhostLevel) *Ghost {
 	return &Ghost{
 		Entity: Entity{
-			Pos:       types.Vector{},
+			Pos:       types.Point{},
 			Dir:       types.Vector{},
 			WantDir:   types.Vector{},
 			Speed:     speed,
 			Color:     color,
 			SpawnTile: types.Tile{spawnX, spawnY},
 		},
-		SkillLevel: skillLevel,
+		SkillLevel:   skillLevel,
+		DecisionTile: types.Tile{X: -1, Y: -1},
 	}
 }
 
-// NewApple creates a new apple entity
-func NewApple(spawnX, spawnY int, color color.RGBA) *Apple {
-	return &Apple{
-		Entity: Entity{
-			Pos:       types.Vector{},
-			Dir:       types.Vector{},
-			WantDir:   types.Vector{},
-			Speed:     0, // Apples don't move
-			Color:     color,
-			SpawnTile: types.Tile{spawnX, spawnY},
-		},
+// NewItem creates an item of the given definition on a tile whose center is
+// at the given position
+func NewItem(id string, def config.ItemDef, tile types.Tile, center types.Point, color color.RGBA) *Item {
+	item := &Item{ID: id, Tile: tile}
+	item.Rehydrate(def, center, color)
+	return item
+}
+
+// Rehydrate rebuilds the fields that are not saved from the item's
+// definition and the center of its tile
+func (it *Item) Rehydrate(def config.ItemDef, center types.Point, color color.RGBA) {
+	it.Sprite = def.Sprite
+	it.Entity = Entity{
+		Pos:       center,
+		PrevPos:   center,
+		Color:     color,
+		Collider:  NewCollider(def.Collider),
+		SpawnTile: it.Tile,
 	}
 }
@@ -1,8 +1,8 @@
 package model
 
 import (
+	"github.com/vladyslavpavlenko/pacman/internal/config"
 	"github.com/vladyslavpavlenko/pacman/internal/types"
-	"image/color"
 )
 
 type Tile byte
@@ -11,7 +11,6 @@ const (
 	TileEmpty Tile = ' '
 	TileWall  Tile = '#'
 	TilePel   Tile = '.'
-	TileApple Tile = 'a'
 )
 
 type Level struct {
@@ -19,32 +18,23 @@ type Level struct {
 	Width        int
 	Height       int
 	TotalPellets int
-	Apples       []*Apple
-}
-
-var DefaultLevelData = []string{
-	"#####################",
-	"#...................#",
-	"#.###.#.###.#.###.###",
-	"#.#...#...#...#...#.#",
-	"#.#.#####.#.#####.#.#",
-	"#...................#",
-	"#.###.#.###.#.###.###",
-	"#.#...#...#...#...#.#",
-	"#.#.#####.#.#####.#.#",
-	"#...................#",
-	"#####################",
+	PelletTiles  []types.Tile // tiles that start with a pellet
+	Items        []*Item
+	NoUpZones    map[types.Tile]bool // tiles where ghosts may not turn upward
+	Exits        map[types.Tile]bool // tiles that complete an exit objective
+	Reservations *Reservations       // tiles held by ghosts; nil when ghosts ignore each other
 }
 
 func New(levelData []string) *Level {
 	if len(levelData) == 0 {
-		levelData = DefaultLevelData
+		levelData = config.DefaultMaze
 	}
 
 	level := &Level{
-		Width:  len(levelData[0]),
-		Height: len(levelData),
-		Apples: make([]*Apple, 0),
+		Width:     len(levelData[0]),
+		Height:    len(levelData),
+		NoUpZones: make(map[types.Tile]bool),
+		Exits:     make(map[types.Tile]bool),
 	}
 
 	level.Grid = make([][]Tile, level.Height)
@@ -60,6 +50,20 @@ func New(levelData []string) *Level {
 			case '.':
 				level.Grid[y][x] = TilePel
 				level.TotalPellets++
+				level.PelletTiles = append(level.PelletTiles, types.Tile{X: x, Y: y})
+			case '^':
+				// Pellet inside a no-up zone
+				level.Grid[y][x] = TilePel
+				level.TotalPellets++
+				level.PelletTiles = append(level.PelletTiles, types.Tile{X: x, Y: y})
+				level.NoUpZones[types.Tile{X: x, Y: y}] = true
+			case '_':
+				// Empty tile inside a no-up zone
+				level.Grid[y][x] = TileEmpty
+				level.NoUpZones[types.Tile{X: x, Y: y}] = true
+			case 'E':
+				level.Grid[y][x] = TileEmpty
+				level.Exits[types.Tile{X: x, Y: y}] = true
 			default:
 				level.Grid[y][x] = TileEmpty
 			}
@@ -77,6 +81,16 @@ func (l *Level) CanWalk(x, y int) bool {
 	return l.Grid[y][x] != TileWall
 }
 
+// IsNoUpZone reports whether ghosts are forbidden to turn upward on the given tile
+func (l *Level) IsNoUpZone(x, y int) bool {
+	return l.NoUpZones[types.Tile{X: x, Y: y}]
+}
+
+// IsExit reports whether the given tile is an exit
+func (l *Level) IsExit(x, y int) bool {
+	return l.Exits[types.Tile{X: x, Y: y}]
+}
+
 // GetTile returns the tile at the given coordinates
 func (l *Level) GetTile(x, y int) Tile {
 	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
@@ -102,6 +116,19 @@ func (l *Level) ConsumePellet(x, y int) bool {
 	return false
 }
 
+// RefillPellets puts back every pellet that has been eaten and returns how
+// many came back
+func (l *Level) RefillPellets() int {
+	refilled := 0
+	for _, t := range l.PelletTiles {
+		if l.GetTile(t.X, t.Y) == TileEmpty {
+			l.SetTile(t.X, t.Y, TilePel)
+			refilled++
+		}
+	}
+	return refilled
+}
+
 // GetDefaultSpawnPoints returns the default spawn points for player and ghosts
 func (l *Level) GetDefaultSpawnPoints() (playerSpawn types.Tile, ghostSpawns []types.Tile) {
 	playerSpawn = types.Tile{X: 1, Y: 1}
@@ -114,18 +141,17 @@ func (l *Level) GetDefaultSpawnPoints() (playerSpawn types.Tile, ghostSpawns []t
 	return
 }
 
-// AddApple adds an apple to the level at the specified position
-func (l *Level) AddApple(x, y int, color color.RGBA) {
-	apple := NewApple(x, y, color)
-	// Position will be set by the caller using physics.TileCenter
-	l.Apples = append(l.Apples, apple)
+// AddItem places an item in the level. Its position is set by the caller
+// using physics.TileCenter.
+func (l *Level) AddItem(item *Item) {
+	l.Items = append(l.Items, item)
 }
 
-// RemoveApple removes an apple from the level
-func (l *Level) RemoveApple(apple *Apple) {
-	for i, a := range l.Apples {
-		if a == apple {
-			l.Apples = append(l.Apples[:i], l.Apples[i+1:]...)
+// RemoveItem removes an item from the level
+func (l *Level) RemoveItem(item *Item) {
+	for i, it := range l.Items {
+		if it == item {
+			l.Items = append(l.Items[:i], l.Items[i+1:]...)
 			break
 		}
 	}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"maps"
+
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// Reservations records which ghost occupies or has claimed each tile, so
+// ghosts can steer around each other instead of stacking up
+type Reservations struct {
+	owners map[types.Tile]*Ghost
+	cost   int
+}
+
+// NewReservations creates an empty reservation table that makes a held tile
+// look cost tiles further away to other ghosts
+func NewReservations(cost int) *Reservations {
+	return &Reservations{owners: make(map[types.Tile]*Ghost), cost: cost}
+}
+
+// Clone returns a copy of the table that can be changed independently. The
+// copy refers to the same ghosts.
+func (r *Reservations) Clone() *Reservations {
+	if r == nil {
+		return nil
+	}
+	return &Reservations{owners: maps.Clone(r.owners), cost: r.cost}
+}
+
+// Clear drops every reservation
+func (r *Reservations) Clear() {
+	clear(r.owners)
+}
+
+// Reserve claims a tile for a ghost unless another ghost already holds it
+func (r *Reservations) Reserve(tile types.Tile, ghost *Ghost) bool {
+	if owner, ok := r.owners[tile]; ok && owner != ghost {
+		return false
+	}
+	r.owners[tile] = ghost
+	return true
+}
+
+// Release drops every reservation held by a ghost except the given tile
+func (r *Reservations) Release(ghost *Ghost, keep types.Tile) {
+	for tile, owner := range r.owners {
+		if owner == ghost && tile != keep {
+			delete(r.owners, tile)
+		}
+	}
+}
+
+// TakenBy reports whether a tile is held by a ghost other than the given one
+func (r *Reservations) TakenBy(tile types.Tile, ghost *Ghost) bool {
+	owner, ok := r.owners[tile]
+	return ok && owner != ghost
+}
+
+// Penalty returns the extra distance a ghost should see on a tile held by another ghost
+func (r *Reservations) Penalty(tile types.Tile, ghost *Ghost) int {
+	if r.TakenBy(tile, ghost) {
+		return r.cost
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package records
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vladyslavpavlenko/pacman/internal/types"
+)
+
+// Run is a finished time-attack run
+type Run struct {
+	Ticks    int   `json:"ticks"`     // ticks taken to clear the level
+	TickRate int   `json:"tick_rate"` // simulation ticks per second the run was played at
+	Splits   []int `json:"splits"`    // ticks at which each pellet quartile was reached
+	Seed     int64 `json:"seed"`      // random seed the run was played with
+
+	// Path is the player's position after each tick, starting from the spawn,
+	// as x and y in sub-pixels
+	Path [][2]types.Fixed `json:"path"`
+}
+
+// Seconds returns how long the run took
+func (r Run) Seconds() float64 {
+	return float64(r.Ticks) / float64(r.TickRate)
+}
+
+// SplitSeconds returns the time of the i-th split, if the run reached it
+func (r Run) SplitSeconds(i int) (float64, bool) {
+	if i < 0 || i >= len(r.Splits) {
+		return 0, false
+	}
+	return float64(r.Splits[i]) / float64(r.TickRate), true
+}
+
+// Store keeps the personal bests in a JSON file
+type Store struct {
+	path  string
+	Bests map[string]Run `json:"bests"` // keyed by Setup.Key
+}
+
+// Setup is everything besides the player that a run's time depends on. Only
+// runs with the same setup are compared.
+type Setup struct {
+	Level      int
+	Difficulty string
+	Momentum   bool // momentum movement instead of classic
+	Adaptive   bool // adaptive difficulty on
+	Seed       int64
+}
+
+// Key identifies a setup in the store
+func (s Setup) Key() string {
+	movement := "classic"
+	if s.Momentum {
+		movement = "momentum"
+	}
+	difficulty := s.Difficulty
+	if s.Adaptive {
+		difficulty += "+adaptive"
+	}
+	return fmt.Sprintf("%d/%s/%s/%d", s.Level, difficulty, movement, s.Seed)
+}
+
+// Load reads the records file at path. A missing file is not an error. On
+// any error an empty store is returned along with it, so callers can log the
+// error and carry on.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Bests: make(map[string]Run)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("read records: %w", err)
+	}
+
+	loaded := &Store{path: path}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return store, fmt.Errorf("parse records %s: %w", path, err)
+	}
+	if loaded.Bests == nil {
+		loaded.Bests = make(map[string]Run)
+	}
+	return loaded, nil
+}
+
+// Best returns the personal best for a key
+func (s *Store) Best(key string) (Run, bool) {
+	run, ok := s.Bests[key]
+	return run, ok
+}
+
+// Submit records a run if it beats the personal best for its key and reports
+// whether it did
+func (s *Store) Submit(key string, run Run) bool {
+	if best, ok := s.Bests[key]; ok && best.Seconds() <= run.Seconds() {
+		return false
+	}
+	s.Bests[key] = run
+	return true
+}
+
+// Save writes the records back to their file. The paths make the file too
+// long to be worth indenting.
+func (s *Store) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encode records: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write records: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package types
+
+import "math"
+
+// FixedShift is the number of fractional bits in a Fixed value
+const FixedShift = 8
+
+// FixedOne is one whole pixel in fixed-point
+const FixedOne Fixed = 1 << FixedShift
+
+// Fixed is a fixed-point number of sub-pixels (1/256 of a pixel). Integer math
+// keeps movement bit-exact across machines and compilers.
+type Fixed int32
+
+// FixedFromInt converts a whole number of pixels to fixed-point
+func FixedFromInt(i int) Fixed {
+	return Fixed(i) << FixedShift
+}
+
+// FixedFromFloat converts pixels to fixed-point, rounding to the nearest sub-pixel
+func FixedFromFloat(f float64) Fixed {
+	return Fixed(math.Round(f * float64(FixedOne)))
+}
+
+// Int returns the whole pixel the value falls in
+func (f Fixed) Int() int {
+	return int(f >> FixedShift)
+}
+
+// Float returns the value in pixels, for rendering
+func (f Fixed) Float() float64 {
+	return float64(f) / float64(FixedOne)
+}
+
+func (f Fixed) Abs() Fixed {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// Point is a position in fixed-point pixels
+type Point struct {
+	X, Y Fixed
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+// Move returns the point moved dist along a grid direction
+func (p Point) Move(dir Vector, dist Fixed) Point {
+	return Point{p.X + Fixed(dir.X)*dist, p.Y + Fixed(dir.Y)*dist}
+}
+
+// Dot returns the dot product of two points treated as vectors, in sub-pixels squared
+func (p Point) Dot(q Point) int64 {
+	return int64(p.X)*int64(q.X) + int64(p.Y)*int64(q.Y)
+}
+
+// LenSq returns the squared length of the point treated as a vector
+func (p Point) LenSq() int64 {
+	return p.Dot(p)
+}
+
+// Vector converts the point to floating-point pixels, for rendering
+func (p Point) Vector() Vector {
+	return Vector{p.X.Float(), p.Y.Float()}
+}
+
+func (p Point) Eq(q Point) bool {
+	return p.X == q.X && p.Y == q.Y
+}
@@ -64,15 +64,36 @@ var ghostClyde []byte
 //go:embed assets/ghosts/blue.png
 var ghostBlue []byte
 
-// Apple sprite
+// Item sprites
 //
 //go:embed assets/other/apple.png
 var appleSprite []byte
 
+//go:embed assets/fruit/cherry.png
+var fruitCherry []byte
+
+//go:embed assets/fruit/strawberry.png
+var fruitStrawberry []byte
+
+//go:embed assets/fruit/orange.png
+var fruitOrange []byte
+
+//go:embed assets/fruit/melon.png
+var fruitMelon []byte
+
+//go:embed assets/fruit/galaxian.png
+var fruitGalaxian []byte
+
+//go:embed assets/fruit/bell.png
+var fruitBell []byte
+
+//go:embed assets/fruit/key.png
+var fruitKey []byte
+
 type AnimationManager struct {
 	sprites      map[string][]*ebiten.Image
 	ghostSprites map[string]*ebiten.Image
-	appleSprite  *ebiten.Image
+	itemSprites  map[string]*ebiten.Image
 }
 
 type AnimationEngine struct {
@@ -85,11 +106,12 @@ func NewAnimationManager() *AnimationManager {
 	am := &AnimationManager{
 		sprites:      make(map[string][]*ebiten.Image),
 		ghostSprites: make(map[string]*ebiten.Image),
+		itemSprites:  make(map[string]*ebiten.Image),
 	}
 
 	am.loadSprites()
 	am.loadGhostSprites()
-	am.loadAppleSprite()
+	am.loadItemSprites()
 	return am
 }
 
@@ -132,8 +154,15 @@ func (am *AnimationManager) loadGhostSprites() {
 	am.ghostSprites["blue"] = am.loadImageFromBytes(ghostBlue)
 }
 
-func (am *AnimationManager) loadAppleSprite() {
-	am.appleSprite = am.loadImageFromBytes(appleSprite)
+func (am *AnimationManager) loadItemSprites() {
+	am.itemSprites["apple"] = am.loadImageFromBytes(appleSprite)
+	am.itemSprites["cherry"] = am.loadImageFromBytes(fruitCherry)
+	am.itemSprites["strawberry"] = am.loadImageFromBytes(fruitStrawberry)
+	am.itemSprites["orange"] = am.loadImageFromBytes(fruitOrange)
+	am.itemSprites["melon"] = am.loadImageFromBytes(fruitMelon)
+	am.itemSprites["galaxian"] = am.loadImageFromBytes(fruitGalaxian)
+	am.itemSprites["bell"] = am.loadImageFromBytes(fruitBell)
+	am.itemSprites["key"] = am.loadImageFromBytes(fruitKey)
 }
 
 func (am *AnimationManager) loadImageFromBytes(data []byte) *ebiten.Image {
@@ -193,9 +222,9 @@ func (am *AnimationManager) GetGhostSprite(ghostColor color.RGBA) *ebiten.Image
 	return am.ghostSprites["blinky"]
 }
 
-// GetAppleSprite returns the apple sprite
-func (am *AnimationManager) GetAppleSprite() *ebiten.Image {
-	return am.appleSprite
+// GetItemSprite returns the item sprite with the given name, or nil for unknown names
+func (am *AnimationManager) GetItemSprite(name string) *ebiten.Image {
+	return am.itemSprites[name]
 }
 
 func NewAnimationEngine(framesPerStep int) *AnimationEngine {
@@ -2,12 +2,15 @@ package renderer
 
 import (
 	"fmt"
+	"image"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/vladyslavpavlenko/pacman/internal/logic/physics"
 	"github.com/vladyslavpavlenko/pacman/internal/model"
+	"github.com/vladyslavpavlenko/pacman/internal/types"
 	"github.com/vladyslavpavlenko/pacman/internal/view/ui"
 )
 
@@ -16,7 +19,9 @@ var (
 	ColorFloor  = color.RGBA{R: 10, G: 10, B: 10, A: 255}
 	ColorPellet = color.RGBA{R: 230, G: 230, B: 230, A: 255}
 	ColorPac    = color.RGBA{R: 255, G: 215, A: 255}
-	ColorApple  = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	ColorReplay = color.RGBA{R: 255, G: 215, A: 90}
+	ColorItem   = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	ColorExit   = color.RGBA{R: 64, G: 220, B: 96, A: 255}
 	ColorGhosts = []color.RGBA{
 		{R: 255, G: 64, B: 64, A: 255},
 		{R: 255, G: 128, B: 255, A: 255},
@@ -28,23 +33,37 @@ var (
 	ColorMenuSelected   = color.RGBA{R: 255, G: 215, B: 0, A: 255}
 	ColorMenuTitle      = color.RGBA{R: 255, G: 215, B: 0, A: 255}
 	ColorSpeedBoost     = color.RGBA{R: 255, G: 255, B: 0, A: 255}
+	ColorAhead          = color.RGBA{R: 64, G: 220, B: 96, A: 255}
+	ColorBehind         = color.RGBA{R: 255, G: 80, B: 80, A: 255}
 )
 
+// whitePixel is the source image for filled shapes drawn with DrawTriangles
+var whitePixel = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
 type Renderer struct {
 	TextRenderer     *TextRenderer
 	AnimationManager *AnimationManager
 	AnimationEngine  *AnimationEngine
-	LastPlayerDir    string // Track last player direction for when stopped
+	LastPlayerDir    string  // Track last player direction for when stopped
+	lastReplayFacing float64 // angle the replay last moved in, kept while it stands still
 }
 
-func New() *Renderer {
+// AnimationStepTime is the number of seconds each player animation frame is shown
+const AnimationStepTime = 16.0 / 60
+
+// New creates a renderer whose animations advance with simulation ticks at the given rate
+func New(tickRate int) *Renderer {
 	textRenderer, err := NewTextRenderer()
 	if err != nil {
 		panic("initialize text renderer: " + err.Error())
 	}
 
 	animationManager := NewAnimationManager()
-	animationEngine := NewAnimationEngine(16) // Change frame every 16 game frames (much slower animation)
+	animationEngine := NewAnimationEngine(max(1, int(math.Round(AnimationStepTime*float64(tickRate)))))
 
 	return &Renderer{
 		TextRenderer:     textRenderer,
@@ -68,6 +87,11 @@ func (r *Renderer) DrawLevel(screen *ebiten.Image, lvl *model.Level) {
 				vector.DrawFilledRect(screen, px, py, float32(physics.TileSize), float32(physics.TileSize), ColorFloor, false)
 			}
 
+			if lvl.IsExit(x, y) {
+				inset := float32(3)
+				vector.StrokeRect(screen, px+inset, py+inset, float32(physics.TileSize)-2*inset, float32(physics.TileSize)-2*inset, 2, ColorExit, false)
+			}
+
 			if lvl.GetTile(x, y) == model.TilePel {
 				cx, cy := px+float32(physics.TileSize)/2, py+float32(physics.TileSize)/2
 				vector.DrawFilledCircle(screen, cx, cy, 3, ColorPellet, false)
@@ -76,11 +100,21 @@ func (r *Renderer) DrawLevel(screen *ebiten.Image, lvl *model.Level) {
 	}
 }
 
-func (r *Renderer) DrawEntity(screen *ebiten.Image, entity *model.Ghost) {
-	r.DrawGhost(screen, entity)
+func (r *Renderer) DrawEntity(screen *ebiten.Image, entity *model.Ghost, alpha float64) {
+	r.DrawGhost(screen, entity, alpha)
 }
 
-func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player) {
+// UpdateAnimations advances sprite animations by one simulation tick
+func (r *Renderer) UpdateAnimations(player *model.Player) {
+	if player.Dir.X != 0 || player.Dir.Y != 0 {
+		r.AnimationEngine.Update()
+	}
+}
+
+// DrawPlayer draws the player alpha of the way between its previous and current tick positions
+func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player, alpha float64) {
+	x, y := player.DrawPos(alpha)
+
 	if player.Dir.X != 0 || player.Dir.Y != 0 {
 		r.LastPlayerDir = r.AnimationManager.GetDirectionFromVector(player.Dir)
 	}
@@ -88,7 +122,6 @@ func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player) {
 	direction := r.LastPlayerDir
 
 	if player.Dir.X != 0 || player.Dir.Y != 0 {
-		r.AnimationEngine.Update()
 		frameCount := r.AnimationManager.GetFrameCount(direction)
 		animationFrame := r.AnimationEngine.GetCurrentFrame(frameCount)
 		sprite := r.AnimationManager.GetSprite(direction, animationFrame)
@@ -98,8 +131,8 @@ func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player) {
 
 			spriteW, spriteH := sprite.Size()
 			op.GeoM.Translate(
-				float64(player.Pos.X)-float64(spriteW)/2,
-				float64(player.Pos.Y)-float64(spriteH)/2,
+				x-float64(spriteW)/2,
+				y-float64(spriteH)/2,
 			)
 
 			screen.DrawImage(sprite, op)
@@ -112,8 +145,8 @@ func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player) {
 
 			spriteW, spriteH := sprite.Size()
 			op.GeoM.Translate(
-				float64(player.Pos.X)-float64(spriteW)/2,
-				float64(player.Pos.Y)-float64(spriteH)/2,
+				x-float64(spriteW)/2,
+				y-float64(spriteH)/2,
 			)
 
 			screen.DrawImage(sprite, op)
@@ -121,7 +154,63 @@ func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *model.Player) {
 	}
 }
 
-func (r *Renderer) DrawGhost(screen *ebiten.Image, ghost *model.Ghost) {
+// DrawReplay draws the translucent replay of a recorded run alpha of the way
+// between two consecutive recorded positions
+func (r *Renderer) DrawReplay(screen *ebiten.Image, prev, cur types.Point, alpha float64) {
+	from, to := prev.Vector(), cur.Vector()
+	if delta := cur.Sub(prev); delta.X != 0 || delta.Y != 0 {
+		r.lastReplayFacing = math.Atan2(float64(delta.Y), float64(delta.X))
+	}
+
+	x := from.X + (to.X-from.X)*alpha
+	y := from.Y + (to.Y-from.Y)*alpha
+	radius := float32(physics.TileSize/2 - 2)
+	drawPie(screen, float32(x), float32(y), radius, r.lastReplayFacing, math.Pi/5, ColorReplay)
+}
+
+// DrawPlayerDeath draws the player's death sequence at the given progress from
+// 0 to 1: the player turns to face up and its mouth opens until it is gone
+func (r *Renderer) DrawPlayerDeath(screen *ebiten.Image, player *model.Player, progress float64) {
+	radius := float32(physics.TileSize/2 - 2)
+	mouth := math.Pi * math.Min(progress, 1)
+	drawPie(screen, float32(player.Pos.X.Float()), float32(player.Pos.Y.Float()), radius, -math.Pi/2, mouth, ColorPac)
+}
+
+// DrawLives draws one player icon per remaining life along the bottom wall
+func (r *Renderer) DrawLives(screen *ebiten.Image, lives int) {
+	const size = physics.TileSize / 2
+	y := float32(screen.Bounds().Dy() - physics.TileSize/2)
+	for i := 0; i < lives; i++ {
+		x := float32(physics.TileSize/2 + i*(size+6))
+		drawPie(screen, x, y, size/2, 0, math.Pi/5, ColorPac)
+	}
+}
+
+// drawPie fills a circle with a wedge of half-angle mouth cut out, centered on facing
+func drawPie(screen *ebiten.Image, cx, cy, radius float32, facing, mouth float64, clr color.RGBA) {
+	if mouth >= math.Pi {
+		return
+	}
+
+	var path vector.Path
+	path.MoveTo(cx, cy)
+	path.Arc(cx, cy, radius, float32(facing+mouth), float32(facing-mouth+2*math.Pi), vector.Clockwise)
+	path.Close()
+
+	vertices, indices := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	for i := range vertices {
+		vertices[i].SrcX, vertices[i].SrcY = 1, 1
+		vertices[i].ColorR = float32(clr.R) / 0xff
+		vertices[i].ColorG = float32(clr.G) / 0xff
+		vertices[i].ColorB = float32(clr.B) / 0xff
+		vertices[i].ColorA = float32(clr.A) / 0xff
+	}
+	screen.DrawTriangles(vertices, indices, whitePixel, &ebiten.DrawTrianglesOptions{FillRule: ebiten.FillRuleNonZero})
+}
+
+// DrawGhost draws a ghost alpha of the way between its previous and current tick positions
+func (r *Renderer) DrawGhost(screen *ebiten.Image, ghost *model.Ghost, alpha float64) {
+	x, y := ghost.DrawPos(alpha)
 	sprite := r.AnimationManager.GetGhostSprite(ghost.Color)
 
 	if sprite != nil {
@@ -129,8 +218,8 @@ func (r *Renderer) DrawGhost(screen *ebiten.Image, ghost *model.Ghost) {
 
 		spriteW, spriteH := sprite.Size()
 		op.GeoM.Translate(
-			float64(ghost.Pos.X)-float64(spriteW)/2,
-			float64(ghost.Pos.Y)-float64(spriteH)/2,
+			x-float64(spriteW)/2,
+			y-float64(spriteH)/2,
 		)
 
 		screen.DrawImage(sprite, op)
@@ -138,8 +227,8 @@ func (r *Renderer) DrawGhost(screen *ebiten.Image, ghost *model.Ghost) {
 		radius := float32(physics.TileSize/2 - 3)
 		vector.DrawFilledCircle(
 			screen,
-			float32(ghost.Pos.X),
-			float32(ghost.Pos.Y),
+			float32(x),
+			float32(y),
 			radius,
 			ghost.Color,
 			false,
@@ -147,14 +236,15 @@ func (r *Renderer) DrawGhost(screen *ebiten.Image, ghost *model.Ghost) {
 	}
 }
 
-func (r *Renderer) DrawGhosts(screen *ebiten.Image, ghosts []*model.Ghost, debugMode bool, ghostAlgorithms []string) {
+func (r *Renderer) DrawGhosts(screen *ebiten.Image, ghosts []*model.Ghost, alpha float64, debugMode bool, ghostAlgorithms []string) {
 	for i, ghost := range ghosts {
-		r.DrawGhost(screen, ghost)
+		r.DrawGhost(screen, ghost, alpha)
 
 		if debugMode && i < len(ghostAlgorithms) {
-			algorithmName := ghostAlgorithms[i]
-			textX := int(ghost.Pos.X)
-			textY := int(ghost.Pos.Y) - 20
+			algorithmName := ghostAlgorithms[i] + "/" + ghost.SkillLevel.String()
+			x, y := ghost.DrawPos(alpha)
+			textX := int(x)
+			textY := int(y) - 20
 
 			textWidth := len(algorithmName) * 6
 			textX -= textWidth / 2
@@ -164,15 +254,16 @@ func (r *Renderer) DrawGhosts(screen *ebiten.Image, ghosts []*model.Ghost, debug
 	}
 }
 
-func (r *Renderer) DrawApple(screen *ebiten.Image, apple *model.Apple) {
-	sprite := r.AnimationManager.GetAppleSprite()
+// DrawItem draws an item's sprite, or a dot in its color when it has none
+func (r *Renderer) DrawItem(screen *ebiten.Image, item *model.Item) {
+	sprite := r.AnimationManager.GetItemSprite(item.Sprite)
 	if sprite != nil {
 		op := &ebiten.DrawImageOptions{}
 
 		spriteW, spriteH := sprite.Size()
 		op.GeoM.Translate(
-			float64(apple.Pos.X)-float64(spriteW)/2,
-			float64(apple.Pos.Y)-float64(spriteH)/2,
+			item.Pos.X.Float()-float64(spriteW)/2,
+			item.Pos.Y.Float()-float64(spriteH)/2,
 		)
 
 		screen.DrawImage(sprite, op)
@@ -180,18 +271,41 @@ func (r *Renderer) DrawApple(screen *ebiten.Image, apple *model.Apple) {
 		radius := float32(physics.TileSize/2 - 6)
 		vector.DrawFilledCircle(
 			screen,
-			float32(apple.Pos.X),
-			float32(apple.Pos.Y),
+			float32(item.Pos.X.Float()),
+			float32(item.Pos.Y.Float()),
 			radius,
-			apple.Color,
+			item.Color,
 			false,
 		)
 	}
 }
 
-func (r *Renderer) DrawApples(screen *ebiten.Image, apples []*model.Apple) {
-	for _, apple := range apples {
-		r.DrawApple(screen, apple)
+// DrawItems draws every item lying in the maze
+func (r *Renderer) DrawItems(screen *ebiten.Image, items []*model.Item) {
+	for _, item := range items {
+		r.DrawItem(screen, item)
+	}
+}
+
+// DrawItemHistory draws the sprites of recently collected items along the
+// bottom wall, right-aligned with the most recent one rightmost
+func (r *Renderer) DrawItemHistory(screen *ebiten.Image, sprites []string) {
+	const spacing = physics.TileSize - 4
+	y := screen.Bounds().Dy() - physics.TileSize/2
+	x := screen.Bounds().Dx() - physics.TileSize/2 - (len(sprites)-1)*spacing
+
+	for i, name := range sprites {
+		cx := x + i*spacing
+		sprite := r.AnimationManager.GetItemSprite(name)
+		if sprite == nil {
+			vector.DrawFilledCircle(screen, float32(cx), float32(y), physics.TileSize/2-6, ColorItem, false)
+			continue
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		spriteW, spriteH := sprite.Size()
+		op.GeoM.Translate(float64(cx-spriteW/2), float64(y-spriteH/2))
+		screen.DrawImage(sprite, op)
 	}
 }
 
@@ -200,10 +314,12 @@ func (r *Renderer) DrawMenu(screen *ebiten.Image, menu *ui.UI, screenWidth, scre
 	r.drawMenu(screen, menu, screenWidth, screenHeight)
 }
 
-func (r *Renderer) DrawWinScreen(screen *ebiten.Image, score int, screenWidth, screenHeight int) {
+// DrawWinScreen draws the final score with any detail lines under it and the
+// instructions for going on
+func (r *Renderer) DrawWinScreen(screen *ebiten.Image, score int, details []string, instructions string, screenWidth, screenHeight int) {
 	screen.Fill(ColorMenuBackground)
 
-	winMsg := "YOU WIN!"
+	winMsg := "LEVEL CLEAR!"
 	titleY := screenHeight / 3
 	leftMargin := screenWidth / 4
 	r.TextRenderer.DrawText(screen, winMsg, leftMargin, titleY, ColorMenuTitle, 32)
@@ -212,8 +328,67 @@ func (r *Renderer) DrawWinScreen(screen *ebiten.Image, score int, screenWidth, s
 	scoreY := screenHeight / 2
 	r.TextRenderer.DrawText(screen, scoreMsg, leftMargin, scoreY, ColorMenuText, 16)
 
-	instructions := "Press R to restart or ESC to return to menu"
-	instructionsY := screenHeight * 2 / 3
+	for i, detail := range details {
+		r.TextRenderer.DrawText(screen, detail, leftMargin, scoreY+30*(i+1), ColorMenuText, 16)
+	}
+
+	instructionsY := max(screenHeight*2/3, scoreY+30*(len(details)+1)+10)
+	r.TextRenderer.DrawText(screen, instructions, leftMargin, instructionsY, ColorMenuText, 12)
+}
+
+// ResultRow is one line of the results screen: a split or the total time,
+// with its difference from the personal best if there is one
+type ResultRow struct {
+	Label string
+	Time  string
+	Delta string
+	Color color.RGBA
+}
+
+// DrawResultsScreen draws the time-attack results with a row per split and
+// the instructions for going on
+func (r *Renderer) DrawResultsScreen(screen *ebiten.Image, title string, score int, rows []ResultRow, instructions string, screenWidth, screenHeight int) {
+	screen.Fill(ColorMenuBackground)
+
+	titleY := screenHeight / 4
+	leftMargin := screenWidth / 4
+	r.TextRenderer.DrawText(screen, title, leftMargin, titleY, ColorMenuTitle, 32)
+
+	scoreMsg := fmt.Sprintf("Score: %d", score)
+	scoreY := screenHeight * 2 / 5
+	r.TextRenderer.DrawText(screen, scoreMsg, leftMargin, scoreY, ColorMenuText, 16)
+
+	for i, row := range rows {
+		y := scoreY + 30*(i+1)
+		r.TextRenderer.DrawText(screen, row.Label, leftMargin, y, ColorMenuText, 16)
+		r.TextRenderer.DrawText(screen, row.Time, leftMargin+100, y, ColorMenuText, 16)
+		if row.Delta != "" {
+			r.TextRenderer.DrawText(screen, row.Delta, leftMargin+220, y, row.Color, 16)
+		}
+	}
+
+	instructionsY := max(screenHeight*3/4, scoreY+30*(len(rows)+1)+10)
+	r.TextRenderer.DrawText(screen, instructions, leftMargin, instructionsY, ColorMenuText, 12)
+}
+
+// DrawGameOverScreen draws the screen shown once the player has run out of
+// lives, with any detail lines under the score and the instructions for going on
+func (r *Renderer) DrawGameOverScreen(screen *ebiten.Image, score int, details []string, instructions string, screenWidth, screenHeight int) {
+	screen.Fill(ColorMenuBackground)
+
+	titleY := screenHeight / 3
+	leftMargin := screenWidth / 4
+	r.TextRenderer.DrawText(screen, "GAME OVER", leftMargin, titleY, ColorMenuTitle, 32)
+
+	scoreMsg := fmt.Sprintf("Final Score: %d", score)
+	scoreY := screenHeight / 2
+	r.TextRenderer.DrawText(screen, scoreMsg, leftMargin, scoreY, ColorMenuText, 16)
+
+	for i, detail := range details {
+		r.TextRenderer.DrawText(screen, detail, leftMargin, scoreY+30*(i+1), ColorMenuText, 16)
+	}
+
+	instructionsY := max(screenHeight*2/3, scoreY+30*(len(details)+1)+10)
 	r.TextRenderer.DrawText(screen, instructions, leftMargin, instructionsY, ColorMenuText, 12)
 }
 
@@ -231,9 +406,30 @@ func (r *Renderer) drawMenu(screen *ebiten.Image, menu *ui.UI, screenWidth, scre
 		textColor := ColorMenuText
 
 		var displayText string
-		if i == 1 {
-			displayText = option + menu.GetSelectedDifficulty().String()
-		} else {
+		switch i {
+		case 1:
+			displayText = option + menu.GetSelectedMode().String()
+		case 2:
+			displayText = option + menu.GetSelectedDifficultyName()
+		case 3:
+			if menu.IsAdaptive() {
+				displayText = option + "On"
+			} else {
+				displayText = option + "Off"
+			}
+		case 4:
+			if menu.IsMomentum() {
+				displayText = option + "Momentum"
+			} else {
+				displayText = option + "Classic"
+			}
+		case 5:
+			if menu.IsReplay() {
+				displayText = option + "On"
+			} else {
+				displayText = option + "Off"
+			}
+		default:
 			displayText = option
 		}
 
@@ -6,4 +6,6 @@ const (
 	StateMenu State = iota
 	StatePlaying
 	StateWon
+	StateGameOver
+	StateResults // time-attack results after clearing a level
 )
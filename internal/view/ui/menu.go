@@ -1,35 +1,49 @@
 package ui
 
 import (
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/vladyslavpavlenko/pacman/internal/config"
+	"github.com/vladyslavpavlenko/pacman/internal/input"
 	"github.com/vladyslavpavlenko/pacman/internal/view"
 )
 
 type UI struct {
 	state          view.State
+	keymap         *input.Keymap
 	selectedOption int
 	selectedDiff   config.Difficulty
+	mode           config.GameMode
+	adaptive       bool
+	momentum       bool
+	replay         bool
 	options        []string
 	difficulties   []config.Difficulty
+	presetNames    []string
 }
 
-func New() *UI {
+// New creates the main menu offering the given difficulty presets in order,
+// navigated with the keys bound in keymap
+func New(presetNames []string, keymap *input.Keymap) *UI {
+	difficulties := make([]config.Difficulty, len(presetNames))
+	for i := range presetNames {
+		difficulties[i] = config.Difficulty(i)
+	}
+
 	return &UI{
 		state:          view.StateMenu,
+		keymap:         keymap,
 		selectedOption: 0,
 		selectedDiff:   config.DifficultyEasy,
 		options: []string{
 			"Start Game",
+			"Mode: ",
 			"Difficulty: ",
+			"Adaptive: ",
+			"Movement: ",
+			"Replay: ",
 			"Exit",
 		},
-		difficulties: []config.Difficulty{
-			config.DifficultyEasy,
-			config.DifficultyMedium,
-			config.DifficultyHard,
-		},
+		difficulties: difficulties,
+		presetNames:  presetNames,
 	}
 }
 
@@ -38,25 +52,33 @@ func (m *UI) Update() (view.State, config.Difficulty, bool) {
 		return m.state, m.selectedDiff, false
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if m.keymap.JustPressed(input.ActionUp) {
 		m.selectedOption = (m.selectedOption - 1 + len(m.options)) % len(m.options)
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+	if m.keymap.JustPressed(input.ActionDown) {
 		m.selectedOption = (m.selectedOption + 1) % len(m.options)
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if m.keymap.JustPressed(input.ActionNext) {
 		switch m.selectedOption {
 		case 0:
 			return view.StatePlaying, m.selectedDiff, true
 		case 1:
+			m.mode = config.GameModes[(int(m.mode)+1)%len(config.GameModes)]
+		case 2:
 			for i, diff := range m.difficulties {
 				if diff == m.selectedDiff {
 					m.selectedDiff = m.difficulties[(i+1)%len(m.difficulties)]
 					break
 				}
 			}
-		case 2:
+		case 3:
+			m.adaptive = !m.adaptive
+		case 4:
+			m.momentum = !m.momentum
+		case 5:
+			m.replay = !m.replay
+		case 6:
 			return view.StateMenu, m.selectedDiff, true
 		}
 	}
@@ -80,6 +102,32 @@ func (m *UI) GetSelectedDifficulty() config.Difficulty {
 	return m.selectedDiff
 }
 
+func (m *UI) GetSelectedDifficultyName() string {
+	if int(m.selectedDiff) < len(m.presetNames) {
+		return m.presetNames[m.selectedDiff]
+	}
+	return "Unknown"
+}
+
+// GetSelectedMode returns the game mode chosen in the menu
+func (m *UI) GetSelectedMode() config.GameMode {
+	return m.mode
+}
+
+func (m *UI) IsAdaptive() bool {
+	return m.adaptive
+}
+
+// IsMomentum reports whether the player should move with momentum instead of at a constant speed
+func (m *UI) IsMomentum() bool {
+	return m.momentum
+}
+
+// IsReplay reports whether time attack should show a replay of the best run
+func (m *UI) IsReplay() bool {
+	return m.replay
+}
+
 func (m *UI) GetOptions() []string {
 	return m.options
 }
@@ -1,13 +1,30 @@
 package main
 
 import (
+	"flag"
 	"log"
 
+	"github.com/vladyslavpavlenko/pacman/internal/config"
 	"github.com/vladyslavpavlenko/pacman/internal/game"
+	"github.com/vladyslavpavlenko/pacman/internal/records"
 )
 
 func main() {
-	if err := game.New().Run(); err != nil {
+	rulesPath := flag.String("rules", "rules.json", "path to the gameplay rules file")
+	recordsPath := flag.String("records", "records.json", "path to the personal best records file")
+	flag.Parse()
+
+	rules, err := config.LoadRules(*rulesPath)
+	if err != nil {
+		log.Printf("%v\nusing built-in rules", err)
+	}
+
+	store, err := records.Load(*recordsPath)
+	if err != nil {
+		log.Printf("%v\nstarting with no records", err)
+	}
+
+	if err := game.New(rules, store).Run(); err != nil {
 		log.Fatal(err)
 	}
 }